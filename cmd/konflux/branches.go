@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+)
+
+// branchLister lists the branches of upstream repositories.
+type branchLister interface {
+	Branches(repository string) ([]string, error)
+}
+
+// gitBranches lists the branches of the GitHub repositories with git
+// ls-remote.
+type gitBranches struct{}
+
+func (gitBranches) Branches(repository string) ([]string, error) {
+	url := fmt.Sprintf("https://github.com/%s.git", repository)
+	out, err := exec.Command("git", "ls-remote", "--heads", url).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing branches of %s: %w", repository, err)
+	}
+	var branches []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		_, ref, ok := strings.Cut(scanner.Text(), "\t")
+		if ok {
+			branches = append(branches, strings.TrimPrefix(ref, "refs/heads/"))
+		}
+	}
+	return branches, scanner.Err()
+}
+
+// fixtureBranches are the branches read from a JSON file, keyed by upstream
+// repository, e.g. {"tektoncd/pipeline": ["main", "release-v1.2.x"]}.
+type fixtureBranches map[string][]string
+
+func readFixtureBranches(path string) (fixtureBranches, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var branches fixtureBranches
+	if err := json.Unmarshal(in, &branches); err != nil {
+		return nil, fmt.Errorf("error while parsing branches %s: %w", path, err)
+	}
+	return branches, nil
+}
+
+func (f fixtureBranches) Branches(repository string) ([]string, error) {
+	branches, ok := f[repository]
+	if !ok {
+		return nil, fmt.Errorf("no branches for %s", repository)
+	}
+	return branches, nil
+}
+
+// validateUpstreamBranches checks that the upstream branches of the release
+// config exist in the upstream repositories.
+func validateUpstreamBranches(configDir, version string, lister branchLister) error {
+	releaseConfig, err := k.ReadResource[k.ReleaseConfig](configDir, "releases", version)
+	if err != nil {
+		return err
+	}
+	log.Printf("Validating the upstream branches of the %s release config...", version)
+
+	components := make([]string, 0, len(releaseConfig.Branches))
+	for component := range releaseConfig.Branches {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	var missing []string
+	for _, component := range components {
+		upstreamBranch := releaseConfig.Branches[component].UpstreamBranch
+		if upstreamBranch == "" {
+			continue
+		}
+		repository, err := k.ReadResource[k.Repository](configDir, "repos", component)
+		if err != nil {
+			log.Printf("skipping %s: no repo config found (%s)", component, err)
+			continue
+		}
+		if repository.Upstream == "" {
+			continue
+		}
+		branches, err := lister.Branches(repository.Upstream)
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("  %s: %s", component, err))
+			continue
+		}
+		if slices.Contains(branches, upstreamBranch) {
+			log.Printf("✔ - %s\t- %s", upstreamBranch, component)
+			continue
+		}
+		log.Printf("X - %s\t- %s", upstreamBranch, component)
+		message := fmt.Sprintf("  %s: branch %q does not exist in %s", component, upstreamBranch, repository.Upstream)
+		if candidates := similarBranches(upstreamBranch, branches); len(candidates) > 0 {
+			message += fmt.Sprintf(", renamed to %s?", strings.Join(candidates, " or "))
+		}
+		missing = append(missing, message)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%d missing upstream branch(es) in the %s release config:\n%s", len(missing), version, strings.Join(missing, "\n"))
+	}
+	log.Printf("OK: all the upstream branches of the %s release config exist", version)
+	return nil
+}
+
+// similarBranches returns the release branches of the same minor version as
+// the missing branch, e.g. release-v1.2.x for release-v1.2.3.
+func similarBranches(branch string, branches []string) []string {
+	if !strings.HasPrefix(branch, "release-") {
+		return nil
+	}
+	version := normalizePatchVersion(extractVersionFromBranch(branch))
+	var similar []string
+	for _, b := range branches {
+		if strings.HasPrefix(b, "release-") && normalizePatchVersion(extractVersionFromBranch(b)) == version {
+			similar = append(similar, b)
+		}
+	}
+	sort.Strings(similar)
+	return similar
+}
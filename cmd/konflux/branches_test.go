@@ -0,0 +1,93 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/hack/internal/konfluxtest"
+)
+
+func TestValidateUpstreamBranches(t *testing.T) {
+	lister, err := readFixtureBranches(filepath.Join("testdata", "branches.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := map[string]string{
+		"repos/tektoncd-pipeline.yaml": "name: tektoncd-pipeline\nupstream: tektoncd/pipeline\n",
+		"repos/tektoncd-hub.yaml":      "name: tektoncd-hub\nupstream: tektoncd/hub\n",
+		"repos/tektoncd-chains.yaml":   "name: tektoncd-chains\nupstream: tektoncd/chains\n",
+		"repos/git-init.yaml":          "name: git-init\nupstream: openshift-pipelines/git-init\n",
+		"repos/tektoncd-cli.yaml":      "name: tektoncd-cli\nupstream: tektoncd/cli\n",
+		"repos/console-plugin.yaml":    "name: console-plugin\n",
+	}
+	tests := []struct {
+		name     string
+		branches string
+		wantErr  []string
+	}{{
+		name:     "ok",
+		branches: "  tektoncd-pipeline:\n    upstream: release-v1.6.x\n  git-init:\n    upstream: main\n  console-plugin:\n    upstream: release-v1.23.x\n  tektoncd-chains: {}\n",
+	}, {
+		name:     "missing",
+		branches: "  tektoncd-pipeline:\n    upstream: release-v1.7.x\n  tektoncd-chains:\n    upstream: release-v0.26.x\n",
+		wantErr: []string{
+			"1 missing upstream branch(es) in the 1.23 release config",
+			`tektoncd-pipeline: branch "release-v1.7.x" does not exist in tektoncd/pipeline`,
+		},
+	}, {
+		name:     "renamed",
+		branches: "  tektoncd-hub:\n    upstream: release-v1.23.2\n  tektoncd-chains:\n    upstream: release-v0.26.1\n",
+		wantErr: []string{
+			"2 missing upstream branch(es)",
+			`tektoncd-hub: branch "release-v1.23.2" does not exist in tektoncd/hub, renamed to release-v1.23.x?`,
+			`tektoncd-chains: branch "release-v0.26.1" does not exist in tektoncd/chains, renamed to release-v0.26.x?`,
+		},
+	}, {
+		name:     "unknown upstream repository",
+		branches: "  tektoncd-cli:\n    upstream: release-v0.43.x\n",
+		wantErr:  []string{"tektoncd-cli: no branches for tektoncd/cli"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"releases/1.23.yaml": "version: \"1.23\"\nrelease-tag: 1.23.0\nbranches:\n" + tt.branches}
+			for name, content := range repos {
+				files[name] = content
+			}
+			err := validateUpstreamBranches(konfluxtest.WriteConfigDir(t, files), "1.23", lister)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validateUpstreamBranches() = nil, want an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validateUpstreamBranches() = %v, want an error containing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSimilarBranches(t *testing.T) {
+	branches := []string{"main", "release-v1.2.x", "release-v1.2.4", "release-v1.3.x", "release-1.2.x"}
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"release-v1.2.3", []string{"release-1.2.x", "release-v1.2.4", "release-v1.2.x"}},
+		{"release-v1.3.0", []string{"release-v1.3.x"}},
+		{"release-v1.4.x", nil},
+		{"main", nil},
+	}
+	for _, tt := range tests {
+		if got := similarBranches(tt.branch, branches); !slices.Equal(got, tt.want) {
+			t.Errorf("similarBranches(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+}
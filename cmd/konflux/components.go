@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	upstreamOperator = "tektoncd/operator"
+	// downstreamComponentsFile is the components file of the downstream
+	// operator, relative to the repository.
+	downstreamComponentsFile = "components.yaml"
+)
+
+// componentSource provides the component versions of an operator
+// components.yaml, keyed by component.
+type componentSource interface {
+	Components() (map[string]operatorComponent, error)
+	// String describes the source in the validation messages.
+	String() string
+}
+
+// remoteComponents reads the components file of a GitHub repository at a
+// branch.
+type remoteComponents struct {
+	repository string
+	branch     string
+	path       string
+}
+
+func (r remoteComponents) String() string {
+	return r.repository + "@" + r.branch
+}
+
+// URL returns the page of the components file, to compare with.
+func (r remoteComponents) URL() string {
+	return fmt.Sprintf("https://github.com/%s/blob/%s/%s", r.repository, r.branch, r.path)
+}
+
+func (r remoteComponents) Components() (map[string]operatorComponent, error) {
+	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", r.repository, r.branch, r.path)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request for %s: %w", r, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s from %s: %w", r.path, r, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s from %s: HTTP %d", r.path, r, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", r.path, err)
+	}
+	return parseComponents(body, r.path)
+}
+
+// fileComponents reads a local components file, either a fixture or the one
+// of an operator checkout.
+type fileComponents struct {
+	path string
+}
+
+func (f fileComponents) String() string {
+	return f.path
+}
+
+func (f fileComponents) Components() (map[string]operatorComponent, error) {
+	body, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseComponents(body, f.path)
+}
+
+func parseComponents(body []byte, name string) (map[string]operatorComponent, error) {
+	var data map[string]operatorComponent
+	if err := yaml.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// newComponentSource returns the source of the component versions to
+// validate the release config against:
+//   - "upstream", tektoncd/operator at the operator upstream branch of the
+//     release,
+//   - "downstream", the downstream operator at its branch for the release,
+//   - the path of an operator checkout, or of a components file.
+func newComponentSource(spec string, config k.Config, releaseConfig k.ReleaseConfig, version string) (componentSource, error) {
+	switch spec {
+	case "", "upstream":
+		branch := releaseConfig.Branches["operator"].UpstreamBranch
+		if branch == "" {
+			branch = "main"
+		}
+		return remoteComponents{repository: upstreamOperator, branch: branch, path: "components.yaml"}, nil
+	case "downstream":
+		branch := releaseBranch(releaseConfig.Branches["operator"], version)
+		return remoteComponents{repository: config.Organization + "/operator", branch: branch.Name, path: downstreamComponentsFile}, nil
+	}
+	info, err := os.Stat(spec)
+	if err != nil {
+		return nil, fmt.Errorf("components source %q is neither upstream, downstream nor a path: %w", spec, err)
+	}
+	if info.IsDir() {
+		return fileComponents{path: filepath.Join(spec, "components.yaml")}, nil
+	}
+	return fileComponents{path: spec}, nil
+}
+
+// releaseBranch returns the branch of a repository for a release version: the
+// downstream branch defaults to the one of the version, and so does the
+// upstream branch of the numbered releases, main for the others.
+func releaseBranch(branch k.Branch, version string) k.Branch {
+	if branch.Name == "" {
+		branch.Name = downstreamBranch(version)
+	}
+	if branch.UpstreamBranch == "" {
+		branch.UpstreamBranch = "main"
+		if _, err := strconv.ParseFloat(version, 64); err == nil {
+			branch.UpstreamBranch = downstreamBranch(version)
+		}
+	}
+	return branch
+}
+
+// downstreamBranch returns the branch of the downstream repositories for a
+// release version.
+func downstreamBranch(version string) string {
+	if _, err := strconv.ParseFloat(version, 64); err != nil {
+		return version
+	}
+	return "release-v" + version + ".x"
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+)
+
+func TestFileComponents(t *testing.T) {
+	components, err := fileComponents{path: filepath.Join("testdata", "components.yaml")}.Components()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]operatorComponent{
+		"pipeline": {GitHub: "tektoncd/pipeline", Version: "v1.12.3"},
+		"triggers": {GitHub: "tektoncd/triggers", Version: "v0.35.0"},
+		"chains":   {GitHub: "tektoncd/chains", Version: "v0.27.1"},
+	}
+	if !reflect.DeepEqual(components, want) {
+		t.Errorf("Components() = %v, want %v", components, want)
+	}
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "components.yaml")
+	if err := os.WriteFile(invalid, []byte("pipeline: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.yaml")} {
+		if _, err := (fileComponents{path: path}).Components(); err == nil {
+			t.Errorf("Components() of %s = nil error, want an error", path)
+		}
+	}
+}
+
+func TestNewComponentSource(t *testing.T) {
+	config := k.Config{Organization: "openshift-pipelines"}
+	configured := k.ReleaseConfig{Branches: map[string]k.Branch{
+		"operator": {Name: "release-v1.23.x-hotfix", UpstreamBranch: "release-v0.78.x"},
+	}}
+	tests := []struct {
+		name          string
+		spec          string
+		releaseConfig k.ReleaseConfig
+		version       string
+		want          componentSource
+		wantErr       string
+	}{{
+		name:          "upstream operator branch",
+		spec:          "upstream",
+		releaseConfig: configured,
+		version:       "1.23",
+		want:          remoteComponents{repository: "tektoncd/operator", branch: "release-v0.78.x", path: "components.yaml"},
+	}, {
+		name:    "upstream main by default",
+		version: "next",
+		want:    remoteComponents{repository: "tektoncd/operator", branch: "main", path: "components.yaml"},
+	}, {
+		name:          "downstream configured branch",
+		spec:          "downstream",
+		releaseConfig: configured,
+		version:       "1.23",
+		want:          remoteComponents{repository: "openshift-pipelines/operator", branch: "release-v1.23.x-hotfix", path: "components.yaml"},
+	}, {
+		name:    "downstream release branch",
+		spec:    "downstream",
+		version: "1.23",
+		want:    remoteComponents{repository: "openshift-pipelines/operator", branch: "release-v1.23.x", path: "components.yaml"},
+	}, {
+		name:    "downstream next branch",
+		spec:    "downstream",
+		version: "next",
+		want:    remoteComponents{repository: "openshift-pipelines/operator", branch: "next", path: "components.yaml"},
+	}, {
+		name: "operator checkout",
+		spec: "testdata",
+		want: fileComponents{path: filepath.Join("testdata", "components.yaml")},
+	}, {
+		name: "components file",
+		spec: filepath.Join("testdata", "components.yaml"),
+		want: fileComponents{path: filepath.Join("testdata", "components.yaml")},
+	}, {
+		name:    "unknown source",
+		spec:    "operator",
+		wantErr: `components source "operator" is neither upstream, downstream nor a path`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newComponentSource(tt.spec, config, tt.releaseConfig, tt.version)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newComponentSource() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("newComponentSource() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseBranch(t *testing.T) {
+	tests := []struct {
+		branch  k.Branch
+		version string
+		want    k.Branch
+	}{
+		{version: "1.23", want: k.Branch{Name: "release-v1.23.x", UpstreamBranch: "release-v1.23.x"}},
+		{version: "next", want: k.Branch{Name: "next", UpstreamBranch: "main"}},
+		{branch: k.Branch{UpstreamBranch: "release-v0.78.x"}, version: "1.23", want: k.Branch{Name: "release-v1.23.x", UpstreamBranch: "release-v0.78.x"}},
+		{branch: k.Branch{Name: "main"}, version: "1.23", want: k.Branch{Name: "main", UpstreamBranch: "release-v1.23.x"}},
+	}
+	for _, tt := range tests {
+		if got := releaseBranch(tt.branch, tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("releaseBranch(%+v, %s) = %+v, want %+v", tt.branch, tt.version, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -30,12 +28,42 @@ func main() {
 	var version = flag.String("version", "next", "Release version to generate config")
 	var dryRun = flag.Bool("dry-run", false, "do not commit or push any changes")
 	var validate = flag.Bool("validate", false, "validate release config component versions against tektoncd/operator and exit")
+	var output = flag.String("output", "", "with -validate, also write the report to the standard output as json, junit or markdown")
+	var fix = flag.Bool("fix", false, "with -validate, set the upstream branches of the release config to the ones of the operator versions")
+	var componentsSource = flag.String("components-source", "upstream", "component versions to validate against: upstream (tektoncd/operator), downstream (the downstream operator), or the path of an operator checkout or components file")
 	var generateTekton = flag.Bool("generate-tekton", true, "validate release config component versions against tektoncd/operator and exit")
+	var validateKinds = flag.Bool("validate-kinds", false, "check generated Kubernetes objects against the fields known for their kind")
+	var strict = flag.Bool("strict", false, "do not update the repositories whose Dockerfiles are missing or cannot be updated, and fail once every application is generated, also on base images not allowed")
+	var validateBranches = flag.Bool("validate-branches", false, "check that the upstream branches of the release config exist and exit")
+	var branchesFile = flag.String("upstream-branches", "", "read the upstream branches from a JSON file instead of git ls-remote")
+	var pin = flag.Bool("pin-images", false, "resolve the images referenced by tag in Dockerfile ARGs to digests, write them to the release config and exit")
 	flag.Parse()
 	configDir := filepath.Dir(*configFile)
 
+	if *pin {
+		if err := pinImages(configDir, filepath.Base(*configFile), *version, registryResolver()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *validateBranches {
+		var lister branchLister = gitBranches{}
+		if *branchesFile != "" {
+			fixture, err := readFixtureBranches(*branchesFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			lister = fixture
+		}
+		if err := validateUpstreamBranches(configDir, *version, lister); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if *validate {
-		if err := validateReleaseConfig(configDir, *version); err != nil {
+		if err := validateReleaseConfig(configDir, filepath.Base(*configFile), *version, *componentsSource, *output, *fix); err != nil {
 			log.Fatal(err)
 		}
 		return
@@ -44,46 +72,36 @@ func main() {
 	log.Printf("configDir: %s", configDir)
 	log.Printf("version: %s", *version)
 
-	// Read the main konflux config using the generic readResource function
+	// Read the main konflux config using the generic ReadResource function
 	config, err := readConfig(configDir, filepath.Base(*configFile))
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	config.ValidateKinds = *validateKinds
+	config.Strict = *strict
 	config.Owners, err = readOwners(configDir)
 	if err != nil {
 		log.Printf("warning: could not read owners.yaml: %v", err)
 		config.Owners = map[string][]string{}
 	}
 
-	// Add main  version by default to add some main specific config.
-	versionConfig := k.ReleaseConfig{
-		Version: k.Release{
-			Version: *version,
-		},
+	applications, err := loadApplications(configDir, config, *version)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if *version != "main" {
-		versionConfig, err = readResource[k.ReleaseConfig](configDir, "releases", *version)
-		if err != nil {
-			log.Fatal(err)
+	// The applications are independent: all of them are generated before
+	// failing with the errors of every one.
+	var failed []error
+	for _, application := range applications {
+		log.Printf("Loaded application: %s", application.Name)
+		if err := k.GenerateConfig(application, *dryRun, *generateTekton); err != nil {
+			log.Printf("Generating %s failed: %s", application.Name, err)
+			failed = append(failed, fmt.Errorf("application %s: %w", application.Name, err))
 		}
-		versionConfig.Version.ImagePrefix = config.ImagePrefix + versionConfig.Version.ImagePrefix
-		versionConfig.Version.Version = *version
 	}
-
-	for _, applicationName := range config.Applications {
-		// Read application using the generic readResource function
-		applications, err := readApplications(configDir, applicationName, versionConfig, config)
-		if err != nil {
-			log.Fatal(err)
-		}
-		for _, application := range applications {
-			log.Printf("Loaded application: %s", application.Name)
-			if err := k.GenerateConfig(application, *dryRun, *generateTekton); err != nil {
-				log.Fatal(err)
-			}
-		}
-
+	if len(failed) > 0 {
+		log.Fatal(errors.Join(failed...))
 	}
 
 	log.Printf("Done:")
@@ -126,47 +144,36 @@ func versionsMatch(branch, upstreamVersion string) bool {
 	return normalizePatchVersion(branchVer) == normalizePatchVersion(upstreamVersion)
 }
 
-func fetchComponentsYAML(operatorBranch string) (map[string]operatorComponent, error) {
-	url := fmt.Sprintf("https://raw.githubusercontent.com/tektoncd/operator/%s/components.yaml", operatorBranch)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request for tektoncd/operator@%s: %w", operatorBranch, err)
+// validateReleaseConfig compares the upstream branches of the release config
+// with the component versions of the source, see newComponentSource. The
+// report is written to the standard output in the output format, if any.
+// With fix, the mismatching upstream branches are set to the branches of the
+// component versions in the release config file.
+func validateReleaseConfig(configDir, configFile, version, sourceSpec, output string, fix bool) error {
+	if err := checkOutputFormat(output); err != nil {
+		return err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	config, err := readConfig(configDir, configFile)
 	if err != nil {
-		return nil, fmt.Errorf("fetching components.yaml from tektoncd/operator@%s: %w", operatorBranch, err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetching components.yaml from tektoncd/operator@%s: HTTP %d", operatorBranch, resp.StatusCode)
+		return err
 	}
-	body, err := io.ReadAll(resp.Body)
+	releaseConfig, err := k.ReadResource[k.ReleaseConfig](configDir, "releases", version)
 	if err != nil {
-		return nil, fmt.Errorf("reading components.yaml: %w", err)
+		return err
 	}
-	var data map[string]operatorComponent
-	if err := yaml.Unmarshal(body, &data); err != nil {
-		return nil, fmt.Errorf("parsing components.yaml: %w", err)
+	releaseConfig.Version.Version = version
+	if err := releaseConfig.Version.Validate(); err != nil {
+		return err
 	}
-	return data, nil
-}
 
-func validateReleaseConfig(configDir, version string) error {
-	releaseConfig, err := readResource[k.ReleaseConfig](configDir, "releases", version)
+	source, err := newComponentSource(sourceSpec, config, releaseConfig, version)
 	if err != nil {
 		return err
 	}
 
-	operatorBranch := releaseConfig.Branches["operator"].UpstreamBranch
-	if operatorBranch == "" {
-		operatorBranch = "main"
-	}
-
-	log.Printf("Validating %s release config against tektoncd/operator@%s...", version, operatorBranch)
+	log.Printf("Validating %s release config against %s...", version, source)
 
-	componentsData, err := fetchComponentsYAML(operatorBranch)
+	componentsData, err := source.Components()
 	if err != nil {
 		return err
 	}
@@ -181,81 +188,163 @@ func validateReleaseConfig(configDir, version string) error {
 		}
 	}
 
-	var mismatches []string
-	for component, branch := range releaseConfig.Branches {
+	components := make([]string, 0, len(releaseConfig.Branches))
+	for component := range releaseConfig.Branches {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	report := validationReport{Version: version, Source: source.String()}
+	fixes := map[string]string{}
+	for _, component := range components {
 		if component == "operator" {
 			continue
 		}
-		upstreamBranch := branch.UpstreamBranch
-		if upstreamBranch == "main" {
-			log.Printf("warning: Operator upstream is targetting the main branch for component %s, should tracking a tag", component)
-		}
-		repository, err := readResource[k.Repository](configDir, "repos", component)
+		upstreamBranch := releaseConfig.Branches[component].UpstreamBranch
+		result := componentResult{Component: component, Branch: upstreamBranch, Status: statusOK}
+		repository, err := k.ReadResource[k.Repository](configDir, "repos", component)
 		if err != nil {
 			log.Printf("skipping %s: no repo config found (%s)", component, err)
+			result.skip("no repo config found")
+			report.add(result)
 			continue
 		}
+		ruled := repository.Validation.UpstreamBranch != "" && releaseConfig.Version.IsNumbered()
+		if err := repository.Validation.Check(releaseConfig.Version, upstreamBranch); err != nil {
+			result.fail(err.Error())
+			log.Printf("X - %s\t- %s: %s", upstreamBranch, component, err)
+		} else if ruled {
+			log.Printf("✔ - %s\t- %s", upstreamBranch, component)
+		}
 		operatorKey, ok := upstreamToOperatorKey[repository.Upstream]
 		if !ok {
 			// Not all components in the downstream config are included in the operator's components.yaml
+			if !ruled && result.Status == statusOK {
+				result.skip("not in " + source.String())
+			}
+			report.add(result)
 			continue
 		}
 		entry, ok := componentsData[operatorKey]
 		if !ok || entry.Version == "" {
 			log.Printf("warning: component %s expected to be in Operator components.yaml but missing or missing version", component)
+			if !ruled && result.Status == statusOK {
+				result.skip("missing version in " + source.String())
+			}
+			report.add(result)
 			continue
 		}
+		result.OperatorVersion = entry.Version
+		var mismatch string
 		if upstreamBranch == "" {
-			mismatches = append(mismatches, fmt.Sprintf("  %s: upstream branch not configured. Operator configured to track %s", component, entry.Version))
-			continue
-		}
-
-		if !versionsMatch(upstreamBranch, entry.Version) {
-			mismatches = append(mismatches, fmt.Sprintf(
-				"  %s: branch %q (%s) does not match operator version %q (%s)",
-				component, upstreamBranch, extractVersionFromBranch(upstreamBranch),
+			mismatch = fmt.Sprintf("upstream branch not configured. Operator configured to track %s", entry.Version)
+		} else if !versionsMatch(upstreamBranch, entry.Version) {
+			mismatch = fmt.Sprintf(
+				"branch %q (%s) does not match operator version %q (%s)",
+				upstreamBranch, extractVersionFromBranch(upstreamBranch),
 				entry.Version, stripV(entry.Version),
-			))
+			)
 			log.Printf("X - %s\t!= %s\t- %s", upstreamBranch, entry.Version, component)
 		} else {
 			log.Printf("✔ - %s\t== %s\t- %s", upstreamBranch, entry.Version, component)
 		}
+		switch {
+		case mismatch == "":
+		case fix:
+			fixes[component] = repository.ReleaseBranch(entry.Version)
+			result.fixed(mismatch, fixes[component])
+		default:
+			result.fail(mismatch)
+		}
+		report.add(result)
 	}
 
-	if len(mismatches) > 0 {
-		return fmt.Errorf("%d version mismatch(es) against tektoncd/operator@%s:\n%s\nCompare with: https://github.com/tektoncd/operator/blob/%s/components.yaml",
-			len(mismatches), operatorBranch, strings.Join(mismatches, "\n"), operatorBranch)
+	if len(fixes) > 0 {
+		if err := fixUpstreamBranches(filepath.Join(configDir, "releases", version+".yaml"), fixes); err != nil {
+			return err
+		}
 	}
 
-	log.Printf("OK: %s release config is in sync with tektoncd/operator@%s", version, operatorBranch)
-	return nil
-}
+	if output != "" {
+		if err := writeValidationReport(os.Stdout, report, output); err != nil {
+			return err
+		}
+	}
 
-// readResource reads any type of resource from YAML files
-func readResource[T any](dir, resourceType, resourceName string) (T, error) {
-	var result T
-	if !strings.HasSuffix(resourceName, ".yaml") {
-		resourceName += ".yaml"
+	if mismatches := report.mismatches(); len(mismatches) > 0 {
+		err := fmt.Errorf("%d version mismatch(es) against %s:\n%s", len(mismatches), source, strings.Join(mismatches, "\n"))
+		if remote, ok := source.(remoteComponents); ok {
+			err = fmt.Errorf("%w\nCompare with: %s", err, remote.URL())
+		}
+		return err
 	}
-	filePath := filepath.Join(dir, resourceType, resourceName)
-	in, err := os.ReadFile(filePath)
 
+	log.Printf("OK: %s release config is in sync with %s", version, source)
+	return nil
+}
+
+// fixUpstreamBranches sets the upstream branches of the components in the
+// release config file, keeping its comments and key order.
+func fixUpstreamBranches(path string, branches map[string]string) error {
+	file, err := k.ReadConfigFile(path)
 	if err != nil {
-		return result, err
+		return err
+	}
+	components := make([]string, 0, len(branches))
+	for component := range branches {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		log.Printf("Fixing %s: upstream set to %s", component, branches[component])
+		file.Set(branches[component], "branches", component, "upstream")
 	}
+	return file.Write()
+}
 
-	if err := yaml.UnmarshalStrict(in, &result); err != nil {
-		return result, fmt.Errorf("error while parsing config %s: %w", filePath, err)
+// Helper functions using the generic ReadResource function
+// loadApplications reads the applications of the release version, none if
+// the release reached its end of life.
+func loadApplications(configDir string, config k.Config, version string) ([]k.Application, error) {
+	// Add main  version by default to add some main specific config.
+	versionConfig := k.ReleaseConfig{
+		Version: k.Release{
+			Version: version,
+		},
+	}
+	if version != "main" {
+		var err error
+		versionConfig, err = k.ReadResource[k.ReleaseConfig](configDir, "releases", version)
+		if err != nil {
+			return nil, err
+		}
+		versionConfig.Version.ImagePrefix = config.ImagePrefix + versionConfig.Version.ImagePrefix
+		versionConfig.Version.Version = version
+		if err := versionConfig.Version.Validate(); err != nil {
+			return nil, err
+		}
+		if versionConfig.Version.EndOfLife(time.Now()) {
+			log.Printf("Release %s reached its end of life, nothing to generate", version)
+			return nil, nil
+		}
 	}
 
-	return result, nil
+	var applications []k.Application
+	for _, applicationName := range config.Applications {
+		// Read application using the generic ReadResource function
+		apps, err := readApplications(configDir, applicationName, versionConfig, config)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, apps...)
+	}
+	return applications, nil
 }
 
-// Helper functions using the generic readResource function
 func readApplications(dir, applicationName string, versionConfig k.ReleaseConfig, config k.Config) ([]k.Application, error) {
 
 	log.Printf("Reading application: %s", applicationName)
-	applicationConfigs, err := readResource[[]k.ApplicationConfig](dir, "applications", applicationName)
+	applicationConfigs, err := k.ReadResource[[]k.ApplicationConfig](dir, "applications", applicationName)
 
 	if err != nil {
 		return []k.Application{}, err
@@ -328,23 +417,7 @@ func updateRepository(name string, repo *k.Repository, a k.Application) error {
 		repo.Url = repository
 	}
 
-	var branchName, upstreamBranch string
-	_, err := strconv.ParseFloat(a.Release.Version, 64)
-	if err != nil {
-		branchName = a.Release.Version
-		upstreamBranch = "main"
-	} else {
-		branchName = "release-v" + a.Release.Version + ".x"
-		upstreamBranch = branchName
-	}
-
-	branch := &repo.Branch
-	if branch.Name == "" {
-		branch.Name = branchName
-	}
-	if branch.UpstreamBranch == "" {
-		branch.UpstreamBranch = upstreamBranch
-	}
+	repo.Branch = releaseBranch(repo.Branch, a.Release.Version)
 
 	// Tekton
 	if repo.Tekton == (k.Tekton{}) {
@@ -363,7 +436,7 @@ func updateRepository(name string, repo *k.Repository, a k.Application) error {
 
 // readRepository reads a repository resource from the repos directory
 func readRepository(dir, repoName string, app *k.Application, branch k.Branch, owners []string) (k.Repository, error) {
-	repository, err := readResource[k.Repository](dir, "repos", repoName)
+	repository, err := k.ReadResource[k.Repository](dir, "repos", repoName)
 	if err != nil {
 		return k.Repository{}, err
 	}
@@ -393,6 +466,13 @@ func UpdateComponent(c *k.Component, repo k.Repository, app k.Application) error
 	if c.Tekton == (k.Tekton{}) {
 		c.Tekton = repo.Tekton
 	}
+	if len(c.BuildPlatforms) == 0 {
+		c.BuildPlatforms = repo.BuildPlatforms
+	}
+	if len(c.BuildPlatforms) == 0 {
+		c.BuildPlatforms = version.BuildPlatforms
+	}
+	c.DockerFileOptions = version.DockerFileOptions.Merge(repo.DockerFileOptions).Merge(c.DockerFileOptions)
 	if c.Dockerfile == "" {
 		Dockerfile, err := k.Eval(".konflux/dockerfiles/{{.Name}}.Dockerfile", c)
 		if err != nil {
@@ -438,7 +518,7 @@ func UpdateComponent(c *k.Component, repo k.Repository, app k.Application) error
 
 // readConfig reads the main konflux config file
 func readConfig(dir, configFile string) (k.Config, error) {
-	return readResource[k.Config](dir, "", configFile)
+	return k.ReadResource[k.Config](dir, "", configFile)
 }
 
 func readOwners(dir string) (map[string][]string, error) {
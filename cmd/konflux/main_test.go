@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+	"github.com/openshift-pipelines/hack/internal/konfluxtest"
+)
+
+const configFile = "../../config/downstream/konflux.yaml"
+
+// TestGeneratedFilesUpToDate generates the Konflux resources of every release
+// and compares them with the files checked in under .konflux.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	configDir, err := filepath.Abs(filepath.Dir(configFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := readConfig(configDir, filepath.Base(configFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	releases, err := filepath.Glob(filepath.Join(configDir, "releases", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, release := range releases {
+		version := strings.TrimSuffix(filepath.Base(release), ".yaml")
+		t.Run(version, func(t *testing.T) {
+			files := generateKonflux(t, configDir, config, version)
+			if len(files) == 0 {
+				t.Fatal("no file generated")
+			}
+			for path, got := range files {
+				want, err := os.ReadFile(filepath.Join(root, path))
+				if err != nil {
+					t.Errorf("%s: %v", path, err)
+					continue
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s differs from the generated file, regenerate it:\n%s", path, got)
+				}
+			}
+		})
+	}
+}
+
+// TestReleaseConfigsFollowRules checks the upstream branches of the release
+// configs against the validation rules of their repositories.
+func TestReleaseConfigsFollowRules(t *testing.T) {
+	configDir := filepath.Dir(configFile)
+	files, err := filepath.Glob(filepath.Join(configDir, "releases", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		version := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		releaseConfig, err := k.ReadResource[k.ReleaseConfig](configDir, "releases", version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		releaseConfig.Version.Version = version
+		for component, branch := range releaseConfig.Branches {
+			repository, err := k.ReadResource[k.Repository](configDir, "repos", component)
+			if err != nil {
+				continue
+			}
+			if err := repository.Validation.Check(releaseConfig.Version, branch.UpstreamBranch); err != nil {
+				t.Errorf("%s: %s: %v", version, component, err)
+			}
+		}
+	}
+}
+
+func TestUpdateComponentBuildPlatforms(t *testing.T) {
+	tests := []struct {
+		name                           string
+		component, repo, release, want []string
+	}{
+		{name: "none"},
+		{name: "release", release: []string{"linux/x86_64"}, want: []string{"linux/x86_64"}},
+		{name: "repository over release", repo: []string{"linux/arm64"}, release: []string{"linux/x86_64"}, want: []string{"linux/arm64"}},
+		{name: "component over repository", component: []string{"linux/s390x"}, repo: []string{"linux/arm64"}, release: []string{"linux/x86_64"}, want: []string{"linux/s390x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := k.Component{Name: "controller", BuildPlatforms: tt.component}
+			repo := k.Repository{Name: "tektoncd-pipeline", BuildPlatforms: tt.repo}
+			app := k.Application{Release: &k.Release{Version: "1.23", BuildPlatforms: tt.release}}
+			if err := UpdateComponent(&c, repo, app); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(c.BuildPlatforms, tt.want) {
+				t.Errorf("BuildPlatforms = %v, want %v", c.BuildPlatforms, tt.want)
+			}
+		})
+	}
+}
+
+// TestReleaseConfigsBuildPlatforms checks that only the operator bundle,
+// built for linux/x86_64 only, sets the platforms of its push PipelineRuns.
+func TestReleaseConfigsBuildPlatforms(t *testing.T) {
+	configDir := filepath.Dir(configFile)
+	config, err := readConfig(configDir, filepath.Base(configFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"1.23", "next"} {
+		applications, err := loadApplications(configDir, config, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, application := range applications {
+			for _, c := range application.Components {
+				var want []string
+				if c.Repository.Name == "operator" && c.Name == "bundle" {
+					want = []string{"linux/x86_64"}
+				}
+				if !slices.Equal(c.BuildPlatforms, want) {
+					t.Errorf("%s: %s/%s: BuildPlatforms = %v, want %v", version, c.Repository.Name, c.Name, c.BuildPlatforms, want)
+				}
+			}
+		}
+	}
+}
+
+// generateKonflux generates the Konflux resources of the release in a
+// temporary directory and returns their content, keyed by file.
+func generateKonflux(t *testing.T, configDir string, config k.Config, version string) map[string][]byte {
+	t.Helper()
+	config.ValidateKinds = true
+	applications, err := loadApplications(configDir, config, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, application := range applications {
+		if err := k.GenerateConfig(application, true, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string][]byte{}
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files[rel] = data
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestValidateReleaseConfigFix fixes the release config of a temporary config
+// directory against the components of testdata: only the upstream branches
+// of the mismatching components change, the rest of the file is kept.
+func TestValidateReleaseConfigFix(t *testing.T) {
+	release := `# Release 1.23, see the operator components
+version: "1.23"
+release-tag: 1.23.0
+branches:
+  # Pipeline, Triggers and Chains follow the operator
+  tektoncd-pipeline:
+    upstream: 'release-v1.11.x' # behind the operator
+  tektoncd-triggers:
+    upstream: "release-v0.35.x"
+  tektoncd-chains:
+    upstream: release-v0.26.x
+  git-init:
+    upstream: release-v1.7.x
+`
+	dir := konfluxtest.WriteConfigDir(t, map[string]string{
+		"konflux.yaml":                 "organization: openshift-pipelines\n",
+		"releases/1.23.yaml":           release,
+		"repos/tektoncd-pipeline.yaml": "name: tektoncd-pipeline\nupstream: tektoncd/pipeline\n",
+		"repos/tektoncd-triggers.yaml": "name: tektoncd-triggers\nupstream: tektoncd/triggers\n",
+		"repos/tektoncd-chains.yaml":   "name: tektoncd-chains\nupstream: tektoncd/chains\n",
+		"repos/git-init.yaml":          "name: tektoncd-git-clone\nupstream: tektoncd-catalog/git-clone\n",
+	})
+	components, err := filepath.Abs(filepath.Join("testdata", "components.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateReleaseConfig(dir, "konflux.yaml", "1.23", components, "", true); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.NewReplacer(
+		"'release-v1.11.x'", "'release-v1.12.x'",
+		"upstream: release-v0.26.x", "upstream: release-v0.27.x",
+	).Replace(release)
+	data, err := os.ReadFile(filepath.Join(dir, "releases", "1.23.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("release file:\n%s\nwant:\n%s", data, want)
+	}
+
+	// The fixed release config is in sync.
+	if err := validateReleaseConfig(dir, "konflux.yaml", "1.23", components, "", false); err != nil {
+		t.Errorf("validating the fixed release config: %v", err)
+	}
+}
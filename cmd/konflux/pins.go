@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+)
+
+// pinImages resolves the images referenced by tag in the Dockerfile ARGs of
+// the release components and writes their digests to the pins of the release
+// configuration. Existing pins are refreshed.
+func pinImages(configDir, configFile, version string, resolver k.DigestResolver) error {
+	config, err := readConfig(configDir, configFile)
+	if err != nil {
+		return err
+	}
+	releaseConfig, err := k.ReadResource[k.ReleaseConfig](configDir, "releases", version)
+	if err != nil {
+		return err
+	}
+	releaseConfig.Version.Version = version
+	previous := releaseConfig.Version.DockerFileOptions.Pins
+
+	var images []string
+	for _, applicationName := range config.Applications {
+		applications, err := readApplications(configDir, applicationName, releaseConfig, config)
+		if err != nil {
+			return err
+		}
+		for _, application := range applications {
+			for _, c := range application.Components {
+				componentImages, err := k.ImageArgs(c)
+				if err != nil {
+					return fmt.Errorf("component %s: %w", c.Name, err)
+				}
+				images = append(images, componentImages...)
+			}
+		}
+	}
+	sort.Strings(images)
+
+	pins, err := k.PinImages(images, resolver)
+	if err != nil {
+		return err
+	}
+
+	releaseFile := filepath.Join(configDir, "releases", version+".yaml")
+	f, err := k.ReadConfigFile(releaseFile)
+	if err != nil {
+		return err
+	}
+	f.SetMap(pins, "docker-file-options", "pins")
+	if err := f.Write(); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tPREVIOUS\tPINNED")
+	for _, image := range slices.Sorted(maps.Keys(pins)) {
+		pinned, old := pins[image], previous[image]
+		if old == "" {
+			old = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", image, old, pinned)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	log.Printf("Pinned %d images in %s", len(pins), releaseFile)
+	return nil
+}
+
+// registryResolver resolves digests with the credentials of the local
+// Docker configuration.
+func registryResolver() k.DigestResolver {
+	return k.RegistryResolver{RemoteOptions: []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}}
+}
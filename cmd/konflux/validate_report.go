@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	statusOK       = "ok"
+	statusMismatch = "mismatch"
+	statusSkipped  = "skipped"
+	statusFixed    = "fixed"
+)
+
+// validationReport is the result of the validation of a release config.
+type validationReport struct {
+	Version    string            `json:"version"`
+	Source     string            `json:"source"`
+	Components []componentResult `json:"components"`
+}
+
+// componentResult is the validation result of a component of the release
+// config.
+type componentResult struct {
+	Component       string `json:"component"`
+	Branch          string `json:"branch"`
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+	Status          string `json:"status"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+func (r *componentResult) fail(reason string) {
+	r.Status = statusMismatch
+	if r.Reason != "" {
+		reason = r.Reason + "; " + reason
+	}
+	r.Reason = reason
+}
+
+// fixed records the mismatch fixed by setting the upstream branch. The result
+// stays a mismatch if it failed a rule of the repository.
+func (r *componentResult) fixed(mismatch, branch string) {
+	reason := fmt.Sprintf("%s, upstream set to %q", mismatch, branch)
+	if r.Status == statusMismatch {
+		r.fail(reason)
+		return
+	}
+	r.Status, r.Reason = statusFixed, reason
+}
+
+func (r *componentResult) skip(reason string) {
+	r.Status, r.Reason = statusSkipped, reason
+}
+
+func (r *validationReport) add(result componentResult) {
+	r.Components = append(r.Components, result)
+}
+
+// mismatches returns the mismatches, one line per component.
+func (r validationReport) mismatches() []string {
+	var mismatches []string
+	for _, c := range r.Components {
+		if c.Status == statusMismatch {
+			mismatches = append(mismatches, fmt.Sprintf("  %s: %s", c.Component, c.Reason))
+		}
+	}
+	return mismatches
+}
+
+func (r validationReport) count(status string) int {
+	n := 0
+	for _, c := range r.Components {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// checkOutputFormat returns an error for unknown report formats.
+func checkOutputFormat(format string) error {
+	switch format {
+	case "", "json", "junit", "markdown":
+		return nil
+	}
+	return fmt.Errorf("unknown output %q, expected json, junit or markdown", format)
+}
+
+func writeValidationReport(w io.Writer, report validationReport, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "junit":
+		return writeJUnitReport(w, report)
+	case "markdown":
+		return writeMarkdownReport(w, report)
+	}
+	return checkOutputFormat(format)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+func writeJUnitReport(w io.Writer, report validationReport) error {
+	suite := junitTestSuite{
+		Name:     fmt.Sprintf("release config %s against %s", report.Version, report.Source),
+		Tests:    len(report.Components),
+		Failures: report.count(statusMismatch),
+		Skipped:  report.count(statusSkipped),
+	}
+	for _, c := range report.Components {
+		testCase := junitTestCase{Name: c.Component, ClassName: "release-config-" + report.Version}
+		switch c.Status {
+		case statusMismatch:
+			testCase.Failure = &junitMessage{Message: c.Reason}
+		case statusSkipped:
+			testCase.Skipped = &junitMessage{Message: c.Reason}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func writeMarkdownReport(w io.Writer, report validationReport) error {
+	icons := map[string]string{statusOK: "✅", statusMismatch: "❌", statusSkipped: "➖", statusFixed: "🔧"}
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Release config %s against `%s`\n\n", report.Version, report.Source)
+	fmt.Fprintf(&b, "%d mismatch(es), %d fixed, %d ok, %d skipped\n\n", report.count(statusMismatch), report.count(statusFixed), report.count(statusOK), report.count(statusSkipped))
+	b.WriteString("| Component | Branch | Operator version | Status | Reason |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, c := range report.Components {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s %s | %s |\n",
+			c.Component, markdownCode(c.Branch), markdownCode(c.OperatorVersion), icons[c.Status], c.Status, markdownCell(c.Reason))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
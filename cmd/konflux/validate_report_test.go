@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func testValidationReport() validationReport {
+	report := validationReport{Version: "1.23", Source: "tektoncd/operator@release-v0.80.x"}
+	report.add(componentResult{Component: "git-init", Branch: "main", Status: statusOK})
+	mismatch := componentResult{Component: "opc", Branch: `release-v1.22.x"<&>`, Status: statusOK}
+	mismatch.fail(`branch "release-v1.22.x\"<&>" does not match "release-v1.23.x"`)
+	report.add(mismatch)
+	fixed := componentResult{Component: "tektoncd-pipeline", Branch: "release-v1.11.x", OperatorVersion: "v1.12.3", Status: statusOK}
+	fixed.fixed("operator tracks v1.12.3 | release-v1.12.x", "release-v1.12.x")
+	report.add(fixed)
+	skipped := componentResult{Component: "tekton-assist", Branch: "release-v0.1.x", Status: statusOK}
+	skipped.skip("not in tektoncd/operator@release-v0.80.x")
+	report.add(skipped)
+	report.add(componentResult{Component: "tektoncd-triggers", Branch: "release-v0.36.x", OperatorVersion: "v0.36.0", Status: statusOK})
+	return report
+}
+
+// TestWriteValidationReport writes the report in every format and compares
+// it with the golden files of testdata/reports. Run with -update to
+// regenerate them.
+func TestWriteValidationReport(t *testing.T) {
+	for format, extension := range map[string]string{"json": ".json", "junit": ".xml", "markdown": ".md"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeValidationReport(&buf, testValidationReport(), format); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "reports", "report"+extension)
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("report differs from %s:\n%s", golden, buf.String())
+			}
+		})
+	}
+
+	if err := writeValidationReport(&bytes.Buffer{}, testValidationReport(), "html"); err == nil {
+		t.Error("writing an html report = nil, want an error")
+	}
+}
+
+// TestWriteValidationReportRoundTrip checks that the branch names and
+// reasons read back unchanged from the json and junit reports.
+func TestWriteValidationReportRoundTrip(t *testing.T) {
+	report := testValidationReport()
+
+	var buf bytes.Buffer
+	if err := writeValidationReport(&buf, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded validationReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, report) {
+		t.Errorf("json report read back as %+v, want %+v", decoded, report)
+	}
+
+	buf.Reset()
+	if err := writeValidationReport(&buf, report, "junit"); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("junit report is not valid XML: %v", err)
+	}
+	if len(suites.Suites) != 1 || len(suites.Suites[0].Cases) != len(report.Components) {
+		t.Fatalf("junit report = %+v, want one suite of %d cases", suites, len(report.Components))
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 5 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("junit suite counts tests=%d failures=%d skipped=%d, want 5, 1 and 1", suite.Tests, suite.Failures, suite.Skipped)
+	}
+	for i, c := range suite.Cases {
+		var message string
+		switch {
+		case c.Failure != nil:
+			message = c.Failure.Message
+		case c.Skipped != nil:
+			message = c.Skipped.Message
+		}
+		want := report.Components[i]
+		if want.Status != statusMismatch && want.Status != statusSkipped {
+			want.Reason = ""
+		}
+		if c.Name != want.Component || message != want.Reason {
+			t.Errorf("junit case %s: %q, want %s: %q", c.Name, message, want.Component, want.Reason)
+		}
+	}
+}
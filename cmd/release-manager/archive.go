@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+)
+
+// archiveRelease marks the release as archived, so that it is not generated
+// anymore, and removes its generated Konflux, ReleasePlanAdmission and CDN
+// artifacts.
+func archiveRelease(configDir, configFile, version string) error {
+	config, err := k.ReadResource[k.Config](configDir, "", configFile)
+	if err != nil {
+		return err
+	}
+	err = updateRelease(configDir, version, false, func(r *releaseFile) error {
+		if !r.Version.IsNumbered() {
+			return fmt.Errorf("release %s cannot be archived", version)
+		}
+		r.Version.Archived = true
+		r.file.Set("true", "archived")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	removed, err := k.RemoveReleaseArtifacts(config, version)
+	if err != nil {
+		return err
+	}
+	log.Printf("Archived release %s, removed %d generated artifacts", version, len(removed))
+	for _, artifact := range removed {
+		log.Printf("  %s", filepath.ToSlash(artifact))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+	"golang.org/x/mod/semver"
+)
+
+// cutRelease branches the release version from next: the next release file,
+// with its upstream branches and Dockerfile options, is copied as the first
+// release candidate of version, then next is moved to the latest upstream
+// releases.
+func cutRelease(configDir, version string, source releaseSource) error {
+	tag, err := k.FirstReleaseCandidate(version)
+	if err != nil {
+		return err
+	}
+	releasePath := filepath.Join(configDir, "releases", version+".yaml")
+	if _, err := os.Stat(releasePath); !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("release %s already exists in %s", version, releasePath)
+	}
+	next, err := os.ReadFile(filepath.Join(configDir, "releases", "next.yaml"))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(releasePath, next, 0o644); err != nil {
+		return err
+	}
+
+	log.Printf("Cutting release %s from next", version)
+	err = updateRelease(configDir, version, false, func(r *releaseFile) error {
+		imageSuffix := r.Version.ImageSuffix
+		if imageSuffix == "" {
+			imageSuffix = defaultImageSuffix
+		}
+		r.setVersion(version, imageSuffix)
+		r.setReleaseTag(tag.String())
+		r.Version.CodeFreeze = false
+		r.file.Set("false", "code-freeze")
+		r.file.MoveToFront("version", "release-tag", "image-suffix", "code-freeze")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := updateRelease(configDir, "next", false, func(r *releaseFile) error {
+		return updateUpstreamVersions(r, configDir, source)
+	}); err != nil {
+		return err
+	}
+	return printVersionBounds(configDir, version)
+}
+
+// printVersionBounds lists the repositories whose min-version or max-version
+// exclude them from the release or make it their first or last one.
+func printVersionBounds(configDir, version string) error {
+	files, err := filepath.Glob(filepath.Join(configDir, "repos", "*.yaml"))
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tMIN-VERSION\tMAX-VERSION\tIN "+version)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		repo, err := k.ReadResource[k.Repository](configDir, "repos", name)
+		if err != nil {
+			return err
+		}
+		if effect := versionBoundEffect(version, repo.MinVersion, repo.MaxVersion); effect != "" {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, orDash(repo.MinVersion), orDash(repo.MaxVersion), effect)
+		}
+	}
+	return w.Flush()
+}
+
+// versionBoundEffect returns how the version bounds of a repository affect
+// the release, the same way the generator includes repositories, or "" when
+// they do not.
+func versionBoundEffect(version, minVersion, maxVersion string) string {
+	v := "v" + version
+	switch {
+	case minVersion != "" && semver.Compare(v, "v"+minVersion) < 0:
+		return "excluded, added in " + minVersion
+	case maxVersion != "" && semver.Compare(v, "v"+maxVersion) > 0:
+		return "excluded, removed after " + maxVersion
+	case minVersion != "" && semver.Compare(v, "v"+minVersion) == 0:
+		return "first release"
+	case maxVersion != "" && semver.Compare(v, "v"+maxVersion) == 0:
+		return "last release"
+	}
+	return ""
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
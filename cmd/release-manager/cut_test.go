@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+	"github.com/openshift-pipelines/hack/internal/konfluxtest"
+)
+
+func TestCutRelease(t *testing.T) {
+	dir := konfluxtest.WriteConfigDir(t, map[string]string{
+		"releases/next.yaml":           "version: next\nrelease-tag: next\ncode-freeze: false\nbranches:\n  tektoncd-pipeline:\n    upstream: release-v1.6.x\n",
+		"repos/tektoncd-pipeline.yaml": "name: tektoncd-pipeline\nupstream: tektoncd/pipeline\nmin-version: \"1.25\"\n",
+	})
+	source := fixtureReleases{"tektoncd/pipeline": {{Tag: "v1.7.0"}}}
+	if err := cutRelease(dir, "1.25", source); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "version: \"1.25\"\nrelease-tag: 1.25.0-RC-1\nimage-suffix: -rhel9\ncode-freeze: false\nbranches:\n  tektoncd-pipeline:\n    upstream: release-v1.6.x\n"
+	if got := readFile(t, filepath.Join(dir, "releases", "1.25.yaml")); got != want {
+		t.Errorf("release file:\n%s\nwant:\n%s", got, want)
+	}
+	release, err := k.ReadResource[k.ReleaseConfig](dir, "releases", "1.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version.Version != "1.25" {
+		t.Errorf("version read back = %q, want 1.25", release.Version.Version)
+	}
+	want = "version: next\nrelease-tag: next\ncode-freeze: false\nbranches:\n  tektoncd-pipeline:\n    upstream: release-v1.7.x\n"
+	if got := readFile(t, filepath.Join(dir, "releases", "next.yaml")); got != want {
+		t.Errorf("next release file:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := cutRelease(dir, "1.25", source); err == nil {
+		t.Error("cutting an existing release = nil, want an error")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+)
+
+// defaultImageSuffix is the image suffix of new releases.
+const defaultImageSuffix = "-rhel9"
+
+const usage = `Usage: release-manager [OPTIONS]
+
+Flags:
+  --action, -a        Action to perform (default update-upstream-versions):
+                        * cut-release              Branch a new release from next as x.y.0-RC-1
+                        * new-release              Create a new release candidate
+                        * new-patch                Create or increment a patch version
+                        * finalize-rc              Finalize a release candidate by dropping -RC suffix
+                        * update-upstream-versions Update upstream related versions
+                        * archive                  Stop generating an end of life release and remove its generated files
+  --version, -v       Release version to operate on, e.g. 1.23, not a release tag like 1.23.0 (default next)
+  --config            Path to the konflux config file (default config/downstream/konflux.yaml)
+  --releases          JSON file with the upstream releases, keyed by repository, used instead of
+                      the GitHub API, e.g. {"tektoncd/pipeline": [{"tag": "v1.2.0", "prerelease": false}]}
+
+RC Workflow:
+  1. Use 'new-release' to create initial x.y.0-RC-1 release
+  2. Continue using 'new-release' to increment RC number
+  3. Use 'finalize-rc' to manually drop RC suffix when ready
+
+During RC mode (before x.y.0), upstream component versions are automatically
+updated when higher versions are released upstream. After x.y.0 release,
+minor versions of upstream components do not auto-update.
+`
+
+func main() {
+	var action, version string
+	flag.StringVar(&action, "action", "update-upstream-versions", "action to perform")
+	flag.StringVar(&action, "a", "update-upstream-versions", "action to perform (shorthand)")
+	flag.StringVar(&version, "version", "next", "release version to operate on")
+	flag.StringVar(&version, "v", "next", "release version to operate on (shorthand)")
+	var configFile = flag.String("config", "config/downstream/konflux.yaml", "path to config file")
+	var releasesFile = flag.String("releases", "", "read the upstream releases from a JSON file instead of the GitHub API")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+	configDir := filepath.Dir(*configFile)
+	if tag, err := k.ParseReleaseTag(version); err == nil {
+		log.Fatalf("%s is a release tag, use the release version %s", version, tag.MinorVersion())
+	}
+
+	log.Printf("Action: %s", action)
+	log.Printf("Version: %s", version)
+
+	var source releaseSource = newGitHubReleases()
+	if *releasesFile != "" {
+		fixture, err := readFixtureReleases(*releasesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		source = fixture
+	}
+
+	var err error
+	switch action {
+	case "cut-release":
+		err = cutRelease(configDir, version, source)
+	case "new-release":
+		err = newRelease(configDir, version, source)
+	case "new-patch":
+		err = updateRelease(configDir, version, false, newPatch)
+	case "finalize-rc":
+		err = updateRelease(configDir, version, false, finalizeRC)
+	case "archive":
+		err = archiveRelease(configDir, filepath.Base(*configFile), version)
+	case "update-upstream-versions":
+		err = updateRelease(configDir, version, true, func(r *releaseFile) error {
+			return updateUpstreamVersions(r, configDir, source)
+		})
+	default:
+		log.Printf("Invalid action: %s", action)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newRelease moves the release file of a version, created when missing, to
+// its next release tag, x.y.0-RC-1 for a new release, and to the current
+// upstream branches.
+func newRelease(configDir, version string, source releaseSource) error {
+	return updateRelease(configDir, version, true, func(r *releaseFile) error {
+		r.setVersion(version, defaultImageSuffix)
+		if err := newPatch(r); err != nil {
+			return err
+		}
+		return updateUpstreamVersions(r, configDir, source)
+	})
+}
+
+// updateRelease applies update to the release file of version and writes it
+// back. The file is created when missing if create is set.
+func updateRelease(configDir, version string, create bool, update func(*releaseFile) error) error {
+	r, err := readReleaseFile(filepath.Join(configDir, "releases", version+".yaml"), version, create)
+	if err != nil {
+		return err
+	}
+	if err := update(r); err != nil {
+		return err
+	}
+	return r.write()
+}
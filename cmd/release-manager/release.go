@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+	"gopkg.in/yaml.v2"
+)
+
+// releaseFile is a release configuration along with its file, so that the
+// changes keep the comments and the order of the keys.
+type releaseFile struct {
+	k.ReleaseConfig
+	file *k.ConfigFile
+}
+
+func readReleaseFile(path, version string, create bool) (*releaseFile, error) {
+	if _, err := os.Stat(path); create && errors.Is(err, fs.ErrNotExist) {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &releaseFile{}
+	if err := yaml.UnmarshalStrict(in, &r.ReleaseConfig); err != nil {
+		return nil, fmt.Errorf("error while parsing config %s: %w", path, err)
+	}
+	r.Version.Version = version
+	if r.file, err = k.ReadConfigFile(path); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *releaseFile) write() error {
+	return r.file.Write()
+}
+
+func (r *releaseFile) setVersion(version, imageSuffix string) {
+	r.Version.Version = version
+	r.Version.ImageSuffix = imageSuffix
+	r.file.Set(version, "version")
+	r.file.Set(imageSuffix, "image-suffix")
+}
+
+func (r *releaseFile) setReleaseTag(tag string) {
+	r.Version.ReleaseTag = tag
+	r.file.Set(tag, "release-tag")
+}
+
+func (r *releaseFile) setUpstreamBranch(repo, branch string) {
+	if r.Branches == nil {
+		r.Branches = map[string]k.Branch{}
+	}
+	b := r.Branches[repo]
+	b.UpstreamBranch = branch
+	r.Branches[repo] = b
+	r.file.Set(branch, "branches", repo, "upstream")
+}
+
+// supported returns an error when the release reached its end of life and is
+// not updated anymore.
+func (r *releaseFile) supported() error {
+	if r.Version.EndOfLife(time.Now()) {
+		return fmt.Errorf("release %s reached its end of life", r.Version.Version)
+	}
+	return nil
+}
+
+// unfreeze lifts the code freeze, the release is being updated.
+func (r *releaseFile) unfreeze() {
+	if !r.Version.CodeFreeze {
+		return
+	}
+	log.Printf("code-freeze is true, setting to false for %s", r.Version.Version)
+	r.Version.CodeFreeze = false
+	r.file.Set("false", "code-freeze")
+}
+
+// newPatch moves a numbered release to its next tag: the first release
+// candidate x.y.0-RC-1 for a new release, then the next release candidate or
+// the next patch version. The tag of the other releases is their version.
+func newPatch(r *releaseFile) error {
+	if err := r.supported(); err != nil {
+		return err
+	}
+	r.unfreeze()
+	if !r.Version.IsNumbered() {
+		log.Printf("next tag: %s", r.Version.Version)
+		r.setReleaseTag(r.Version.Version)
+		return nil
+	}
+	log.Printf("Current tag %s", r.Version.ReleaseTag)
+	var next k.ReleaseTag
+	if r.Version.ReleaseTag == "" {
+		var err error
+		if next, err = k.FirstReleaseCandidate(r.Version.Version); err != nil {
+			return err
+		}
+	} else {
+		if err := r.Version.Validate(); err != nil {
+			return err
+		}
+		current, _ := r.Version.Tag()
+		next = current.NextPatch()
+		if current.IsRC() {
+			next = current.NextRC()
+		}
+	}
+	log.Printf("next tag: %s", next)
+	r.setReleaseTag(next.String())
+	return nil
+}
+
+// finalizeRC drops the release candidate suffix of the release tag.
+func finalizeRC(r *releaseFile) error {
+	if err := r.supported(); err != nil {
+		return err
+	}
+	if err := r.Version.Validate(); err != nil {
+		return err
+	}
+	tag, err := r.Version.Tag()
+	if err != nil || !tag.IsRC() {
+		log.Printf("Version %s is not an RC version. Nothing to finalize.", r.Version.ReleaseTag)
+		return nil
+	}
+	final := tag.Finalize()
+	r.setReleaseTag(final.String())
+	log.Printf("Finalized RC release: %s", final)
+	return nil
+}
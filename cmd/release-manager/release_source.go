@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// upstreamRelease is a release of an upstream repository.
+type upstreamRelease struct {
+	Tag        string `json:"tag"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// releaseSource lists the releases of the upstream repositories.
+type releaseSource interface {
+	Releases(repository string) ([]upstreamRelease, error)
+}
+
+// gitHubReleases lists the releases with the GitHub API, authenticated with
+// the GITHUB_TOKEN or GH_TOKEN environment variable when set.
+type gitHubReleases struct {
+	baseURL string
+	token   string
+}
+
+func newGitHubReleases() gitHubReleases {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return gitHubReleases{baseURL: "https://api.github.com", token: token}
+}
+
+// Releases returns the 100 most recent releases, drafts excluded.
+func (g gitHubReleases) Releases(repository string) ([]upstreamRelease, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=100", g.baseURL, repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request for %s releases: %w", repository, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s releases: %w", repository, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s releases: HTTP %d", repository, resp.StatusCode)
+	}
+	var data []struct {
+		TagName    string `json:"tag_name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("parsing %s releases: %w", repository, err)
+	}
+	var releases []upstreamRelease
+	for _, d := range data {
+		if !d.Draft {
+			releases = append(releases, upstreamRelease{Tag: d.TagName, Prerelease: d.Prerelease})
+		}
+	}
+	return releases, nil
+}
+
+// fixtureReleases are the releases read from a JSON file, keyed by upstream
+// repository, e.g. {"tektoncd/pipeline": [{"tag": "v1.2.0"}]}.
+type fixtureReleases map[string][]upstreamRelease
+
+func readFixtureReleases(path string) (fixtureReleases, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var releases fixtureReleases
+	if err := json.Unmarshal(in, &releases); err != nil {
+		return nil, fmt.Errorf("error while parsing releases %s: %w", path, err)
+	}
+	return releases, nil
+}
+
+func (f fixtureReleases) Releases(repository string) ([]upstreamRelease, error) {
+	releases, ok := f[repository]
+	if !ok {
+		return nil, fmt.Errorf("no releases for %s", repository)
+	}
+	return releases, nil
+}
+
+// latestRelease returns the tag of the highest release, prereleases and tags
+// which are not semantic versions excluded.
+func latestRelease(releases []upstreamRelease) (string, bool) {
+	var latest string
+	for _, r := range releases {
+		v := semverTag(r.Tag)
+		if r.Prerelease || !semver.IsValid(v) || semver.Prerelease(v) != "" {
+			continue
+		}
+		if latest == "" || semver.Compare(v, semverTag(latest)) > 0 {
+			latest = r.Tag
+		}
+	}
+	return latest, latest != ""
+}
+
+// semverTag returns the tag with the "v" prefix of semantic versions.
+func semverTag(tag string) string {
+	if strings.HasPrefix(tag, "v") {
+		return tag
+	}
+	return "v" + tag
+}
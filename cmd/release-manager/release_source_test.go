@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+)
+
+func TestGitHubReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/tektoncd/pipeline/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q, want the token", got)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "github-releases.json"))
+	}))
+	defer server.Close()
+	source := gitHubReleases{baseURL: server.URL, token: "token"}
+
+	releases, err := source.Releases("tektoncd/pipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []upstreamRelease{{Tag: "v1.7.0-rc.1", Prerelease: true}, {Tag: "v1.6.10"}, {Tag: "v1.6.9"}}
+	if !reflect.DeepEqual(releases, want) {
+		t.Errorf("Releases() = %+v, want the releases without the draft %+v", releases, want)
+	}
+	if _, err := source.Releases("tektoncd/unknown"); err == nil {
+		t.Error("Releases() of an unknown repository = nil error, want an error")
+	}
+}
+
+func TestLatestRelease(t *testing.T) {
+	source, err := readFixtureReleases(filepath.Join("testdata", "releases.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		repository string
+		want       string
+		wantOK     bool
+		wantErr    bool
+	}{
+		// Prereleases are skipped, v1.6.10 is above v1.6.9.
+		{repository: "tektoncd/pipeline", want: "v1.6.10", wantOK: true},
+		// Tags which are not semantic versions are skipped.
+		{repository: "tektoncd/hub", want: "v1.23.12", wantOK: true},
+		// Versions with a prerelease suffix are skipped, even if not flagged.
+		{repository: "tektoncd/chains"},
+		{repository: "tektoncd/cli"},
+		{repository: "tektoncd/unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		releases, err := source.Releases(tt.repository)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Releases(%s) = nil error, want an error", tt.repository)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := latestRelease(releases)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("latestRelease(%s) = %q, %t, want %q, %t", tt.repository, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if _, err := readFixtureReleases(filepath.Join("testdata", "github-releases.json")); err == nil {
+		t.Error("readFixtureReleases() of a release list = nil error, want an error")
+	}
+}
+
+func TestOlderBranch(t *testing.T) {
+	tests := []struct {
+		branch, current string
+		want            bool
+	}{
+		{"release-v1.5.x", "release-v1.6.x", true},
+		{"release-v1.6.x", "release-v1.6.x", false},
+		{"release-v1.7.x", "release-v1.6.x", false},
+		{"release-v1.9.x", "release-v1.10.x", true},
+		{"release-v1.10.x", "release-v1.9.x", false},
+		{"release-v1.23.9", "release-v1.23.12", true},
+		{"release-v1.23.12", "release-v1.23.9", false},
+		// A patch branch is compared with the minor version of a .x branch.
+		{"release-v1.6.3", "release-v1.6.x", false},
+		{"release-v1.5.9", "release-v1.6.x", true},
+		{"release-v1.6.x", "release-v1.6.3", false},
+		// Other branches are never compared.
+		{"release-v1.6.x", "main", false},
+		{"main", "release-v1.6.x", false},
+		{"release-v1.6.x", "", false},
+		{"release-next", "release-v1.6.x", false},
+	}
+	for _, tt := range tests {
+		if got := olderBranch(tt.branch, tt.current); got != tt.want {
+			t.Errorf("olderBranch(%q, %q) = %t, want %t", tt.branch, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestNextUpstreamBranch(t *testing.T) {
+	source := fixtureReleases{
+		"tektoncd/pipeline": {{Tag: "v1.6.10"}, {Tag: "v1.7.0-rc.1", Prerelease: true}},
+		"tektoncd/hub":      {{Tag: "v1.23.12"}},
+	}
+	tests := []struct {
+		name     string
+		repo     string
+		patch    bool
+		current  string
+		want     string
+		wantNote string
+	}{
+		{name: "moves forward", repo: "tektoncd/pipeline", current: "release-v1.5.x", want: "release-v1.6.x"},
+		{name: "never downgrades", repo: "tektoncd/pipeline", current: "release-v1.8.x", want: "release-v1.8.x", wantNote: "not downgrading to release-v1.6.x"},
+		{name: "patch branch", repo: "tektoncd/hub", patch: true, current: "release-v1.23.9", want: "release-v1.23.12"},
+		{name: "patch branch never downgrades", repo: "tektoncd/hub", patch: true, current: "release-v1.24.0", want: "release-v1.24.0", wantNote: "not downgrading to release-v1.23.12"},
+		{name: "moving branch", repo: "tektoncd/pipeline", current: "main", want: "release-v1.6.x"},
+		{name: "no releases", repo: "tektoncd/unknown", current: "release-v1.0.x", want: "release-v1.0.x", wantNote: "no releases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, note := nextUpstreamBranch(source, k.Repository{Upstream: tt.repo, UsePatchBranch: tt.patch}, tt.current)
+			if got != tt.want || note != tt.wantNote {
+				t.Errorf("nextUpstreamBranch() = %q, %q, want %q, %q", got, note, tt.want, tt.wantNote)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/hack/internal/konfluxtest"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUpdateReleaseTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(*releaseFile) error
+		version string
+		release string
+		want    string
+		wantErr string
+	}{{
+		name:    "new patch of an unnumbered release",
+		update:  newPatch,
+		version: "next",
+		release: "version: next\n",
+		want:    "version: next\nrelease-tag: next\n",
+	}, {
+		name:    "first release candidate",
+		update:  newPatch,
+		version: "1.23",
+		release: "version: \"1.23\"\nimage-suffix: \"-rhel9\"\n",
+		want:    "version: \"1.23\"\nimage-suffix: \"-rhel9\"\nrelease-tag: 1.23.0-RC-1\n",
+	}, {
+		name:    "next release candidate",
+		update:  newPatch,
+		version: "1.23",
+		release: "version: \"1.23\"\n# The tag of the images\nrelease-tag: 1.23.0-RC-9\n",
+		want:    "version: \"1.23\"\n# The tag of the images\nrelease-tag: 1.23.0-RC-10\n",
+	}, {
+		name:    "next patch",
+		update:  newPatch,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.23.9\n",
+		want:    "version: \"1.23\"\nrelease-tag: 1.23.10\n",
+	}, {
+		name:    "new patch lifts the code freeze",
+		update:  newPatch,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.23.1\ncode-freeze: true\n",
+		want:    "version: \"1.23\"\nrelease-tag: 1.23.2\ncode-freeze: false\n",
+	}, {
+		name:    "malformed tag",
+		update:  newPatch,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.23.0-rc1\n",
+		wantErr: `invalid release tag "1.23.0-rc1"`,
+	}, {
+		name:    "tag of another release",
+		update:  newPatch,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.22.3\n",
+		wantErr: "belongs to release 1.22",
+	}, {
+		name:    "end of life",
+		update:  newPatch,
+		version: "1.15",
+		release: "version: \"1.15\"\nrelease-tag: 1.15.4\neol: \"2025-01-01\"\n",
+		wantErr: "reached its end of life",
+	}, {
+		name:    "malformed end of life",
+		update:  newPatch,
+		version: "1.15",
+		release: "version: \"1.15\"\nrelease-tag: 1.15.4\neol: \"2025-13-01\"\n",
+		wantErr: `invalid eol date "2025-13-01"`,
+	}, {
+		name:    "finalize a release candidate",
+		update:  finalizeRC,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.23.0-RC-10 # RC\n",
+		want:    "version: \"1.23\"\nrelease-tag: 1.23.0 # RC\n",
+	}, {
+		name:    "finalize a final release",
+		update:  finalizeRC,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.23.1\n",
+		want:    "version: \"1.23\"\nrelease-tag: 1.23.1\n",
+	}, {
+		name:    "finalize a malformed tag",
+		update:  finalizeRC,
+		version: "1.23",
+		release: "version: \"1.23\"\nrelease-tag: 1.23.0-RC-0\n",
+		wantErr: "release candidates start at RC-1",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := konfluxtest.WriteConfigDir(t, map[string]string{"releases/" + tt.version + ".yaml": tt.release})
+			err := updateRelease(dir, tt.version, false, tt.update)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("updateRelease() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readFile(t, filepath.Join(dir, "releases", tt.version+".yaml")); got != tt.want {
+				t.Errorf("release file:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRelease(t *testing.T) {
+	source := fixtureReleases{
+		"tektoncd/pipeline": {{Tag: "v1.6.0"}, {Tag: "v1.7.0-rc.1", Prerelease: true}},
+		"tektoncd/hub":      {{Tag: "v1.23.12"}},
+	}
+	files := map[string]string{
+		"releases/1.22.yaml":             "version: \"1.22\"\nrelease-tag: 1.22.0-RC-1\n",
+		"repos/tektoncd-pipeline.yaml":   "name: tektoncd-pipeline\nupstream: tektoncd/pipeline\n",
+		"repos/tektoncd-hub.yaml":        "name: tektoncd-hub\nupstream: tektoncd/hub\nuse-patch-branch: true\n",
+		"repos/operator.yaml":            "name: operator\nupstream: tektoncd/operator\n",
+		"repos/openshift-pipelines.yaml": "name: openshift-pipelines\n",
+	}
+
+	t.Run("new version", func(t *testing.T) {
+		dir := konfluxtest.WriteConfigDir(t, files)
+		if err := newRelease(dir, "1.23", source); err != nil {
+			t.Fatal(err)
+		}
+		want := `version: "1.23"
+image-suffix: -rhel9
+release-tag: 1.23.0-RC-1
+branches:
+  tektoncd-hub:
+    upstream: release-v1.23.12
+  tektoncd-pipeline:
+    upstream: release-v1.6.x
+`
+		if got := readFile(t, filepath.Join(dir, "releases", "1.23.yaml")); got != want {
+			t.Errorf("release file:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("existing release candidate", func(t *testing.T) {
+		dir := konfluxtest.WriteConfigDir(t, files)
+		if err := newRelease(dir, "1.22", source); err != nil {
+			t.Fatal(err)
+		}
+		want := `version: "1.22"
+release-tag: 1.22.0-RC-2
+image-suffix: -rhel9
+branches:
+  tektoncd-hub:
+    upstream: release-v1.23.12
+  tektoncd-pipeline:
+    upstream: release-v1.6.x
+`
+		if got := readFile(t, filepath.Join(dir, "releases", "1.22.yaml")); got != want {
+			t.Errorf("release file:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	k "github.com/openshift-pipelines/hack/internal/konflux"
+	"golang.org/x/mod/semver"
+)
+
+// operatorRepository is not tracked by its releases, the operator is released
+// along with the product.
+const operatorRepository = "tektoncd/operator"
+
+// updateUpstreamVersions points the upstream branch of every repository to the
+// one of its latest upstream release. The branches never move to an older
+// release, and do not move during the code freeze, except for the
+// repositories which are exceptions.
+func updateUpstreamVersions(r *releaseFile, configDir string, source releaseSource) error {
+	if err := r.supported(); err != nil {
+		return err
+	}
+	log.Printf("Updating upstream version for release: %s", r.Version.Version)
+
+	files, err := filepath.Glob(filepath.Join(configDir, "repos", "*.yaml"))
+	if err != nil {
+		return err
+	}
+	var changes []upstreamChange
+	for _, file := range files {
+		downstream := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		repo, err := k.ReadResource[k.Repository](configDir, "repos", downstream)
+		if err != nil {
+			return err
+		}
+		if repo.Upstream == "" || repo.Upstream == operatorRepository {
+			continue
+		}
+		name := repo.Name
+		if name == "" {
+			name = downstream
+		}
+		change := upstreamChange{repository: downstream, upstream: repo.Upstream, before: r.Branches[downstream].UpstreamBranch}
+		change.after = change.before
+		if r.Version.Frozen(name) {
+			change.note = "code freeze"
+		} else {
+			change.after, change.note = nextUpstreamBranch(source, repo, change.before)
+		}
+		if change.after != change.before {
+			r.setUpstreamBranch(downstream, change.after)
+		}
+		changes = append(changes, change)
+	}
+	return printUpstreamChanges(os.Stdout, changes)
+}
+
+// nextUpstreamBranch returns the branch of the latest upstream release of the
+// repository, or the current branch with the reason it is kept.
+func nextUpstreamBranch(source releaseSource, repo k.Repository, current string) (string, string) {
+	releases, err := source.Releases(repo.Upstream)
+	if err != nil {
+		log.Printf("⚠ No releases found for %s: %v", repo.Upstream, err)
+		return current, "no releases"
+	}
+	latest, ok := latestRelease(releases)
+	if !ok {
+		return current, "no releases"
+	}
+	branch := repo.ReleaseBranch(latest)
+	if olderBranch(branch, current) {
+		return current, "not downgrading to " + branch
+	}
+	return branch, ""
+}
+
+// upstreamChange is the update of the upstream branch of a repository.
+type upstreamChange struct {
+	repository, upstream string
+	before, after        string
+	note                 string
+}
+
+func printUpstreamChanges(out io.Writer, changes []upstreamChange) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tUPSTREAM\tBEFORE\tAFTER\tNOTE")
+	for _, c := range changes {
+		note := c.note
+		if note == "" && c.before == c.after {
+			note = "unchanged"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.repository, c.upstream, orDash(c.before), orDash(c.after), note)
+	}
+	return w.Flush()
+}
+
+// olderBranch reports whether the release branch is for an older release
+// than the current one. Branches which are not release branches, e.g. main,
+// are not compared.
+func olderBranch(branch, current string) bool {
+	v, c := branchVersion(branch), branchVersion(current)
+	if v == "" || c == "" {
+		return false
+	}
+	if strings.HasSuffix(branch, ".x") || strings.HasSuffix(current, ".x") {
+		v, c = semver.MajorMinor(v), semver.MajorMinor(c)
+	}
+	return semver.Compare(v, c) < 0
+}
+
+// branchVersion returns the semantic version of a release branch, e.g. v1.2
+// for release-v1.2.x or v1.2.3 for release-v1.2.3, "" for the other branches.
+func branchVersion(branch string) string {
+	version, ok := strings.CutPrefix(branch, "release-")
+	if !ok {
+		return ""
+	}
+	version = semverTag(strings.TrimSuffix(version, ".x"))
+	if !semver.IsValid(version) {
+		return ""
+	}
+	return version
+}
@@ -0,0 +1,45 @@
+package konflux
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ReleaseArtifacts returns the files and directories generated for a release
+// version: the Konflux applications, the ReleasePlanAdmissions and the CDN
+// product versions.
+func ReleaseArtifacts(config Config, version string) ([]string, error) {
+	patterns := []string{
+		filepath.Join(konfluxDir, config.Product, hyphenize(version)),
+		filepath.Join(konfluxDir, config.RPADir, hyphenize(config.Product+"-"+version)+"-*.yaml"),
+		filepath.Join(konfluxDir, config.CdnProductDir, version+".*.yaml"),
+	}
+	var artifacts []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, matches...)
+	}
+	sort.Strings(artifacts)
+	return artifacts, nil
+}
+
+// RemoveReleaseArtifacts removes the generated artifacts of a release version
+// and returns them.
+func RemoveReleaseArtifacts(config Config, version string) ([]string, error) {
+	artifacts, err := ReleaseArtifacts(config, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, artifact := range artifacts {
+		if err := os.RemoveAll(artifact); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return artifacts, nil
+}
@@ -0,0 +1,80 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRemoveReleaseArtifacts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := Config{
+		Product:       "openshift-pipelines",
+		RPADir:        "config/ReleasePlanAdmission/tekton-ecosystem",
+		CdnProductDir: "data/external/developer-portal/openshift-pipelines",
+	}
+	applications := filepath.Join(konfluxDir, config.Product)
+	rpaDir := filepath.Join(konfluxDir, config.RPADir)
+	cdnDir := filepath.Join(konfluxDir, config.CdnProductDir)
+	// The artifacts of 1.2 must not match the ones of 1.20 to 1.29.
+	removed := []string{
+		filepath.Join(applications, "1-2", "core", "application.yaml"),
+		filepath.Join(rpaDir, "openshift-pipelines-1-2-core-prod.yaml"),
+		filepath.Join(rpaDir, "openshift-pipelines-1-2-bundle-stage.yaml"),
+		filepath.Join(cdnDir, "1.2.0.yaml"),
+		filepath.Join(cdnDir, "1.2.3.yaml"),
+	}
+	kept := []string{
+		filepath.Join(applications, "1-20", "core", "application.yaml"),
+		filepath.Join(applications, "1-29", "bundle", "application.yaml"),
+		filepath.Join(rpaDir, "openshift-pipelines-1-20-core-prod.yaml"),
+		filepath.Join(rpaDir, "openshift-pipelines-1-25-bundle-stage.yaml"),
+		filepath.Join(cdnDir, "1.20.0.yaml"),
+		filepath.Join(cdnDir, "1.29.1.yaml"),
+	}
+	for _, file := range append(slices.Clone(removed), kept...) {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		filepath.Join(applications, "1-2"),
+		filepath.Join(rpaDir, "openshift-pipelines-1-2-bundle-stage.yaml"),
+		filepath.Join(rpaDir, "openshift-pipelines-1-2-core-prod.yaml"),
+		filepath.Join(cdnDir, "1.2.0.yaml"),
+		filepath.Join(cdnDir, "1.2.3.yaml"),
+	}
+	slices.Sort(want)
+	got, err := ReleaseArtifacts(config, "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ReleaseArtifacts() = %v, want %v", got, want)
+	}
+	got, err = RemoveReleaseArtifacts(config, "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("RemoveReleaseArtifacts() = %v, want %v", got, want)
+	}
+	for _, file := range removed {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", file, err)
+		}
+	}
+	for _, file := range kept {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("%s removed: %v", file, err)
+		}
+	}
+	if got, err := ReleaseArtifacts(config, "1.2"); err != nil || len(got) != 0 {
+		t.Errorf("ReleaseArtifacts() after removal = %v, %v, want none", got, err)
+	}
+}
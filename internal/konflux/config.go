@@ -1,15 +1,22 @@
 package konflux
 
 import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"slices"
 	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
 	Organization   string `yaml:"organization"`
 	Namespace      string `yaml:"namespace"`
 	Applications   []string
-	Versions       []string
 	Repositories   []Repository        `yaml:"repos"`
 	ImagePrefix    string              `yaml:"image-prefix"`
 	ImageSuffix    string              `yaml:"image-suffix"`
@@ -18,6 +25,19 @@ type Config struct {
 	PyxisConfigDir string              `yaml:"pyxis-config-dir"`
 	CdnProductDir  string              `yaml:"cdn-product-dir"`
 	Owners         map[string][]string `yaml:"-"`
+	// AllowedBaseImages are path.Match patterns of the repositories Dockerfile
+	// stages may be built from. The base images of the updated Dockerfiles
+	// are checked once every repository is generated. No check is done when
+	// empty.
+	AllowedBaseImages []string `yaml:"allowed-base-images"`
+	// ValidateKinds enables checking the generated Kubernetes objects against
+	// the fields known for their kind.
+	ValidateKinds bool `yaml:"-"`
+	// Strict skips the repositories whose Dockerfiles are missing or cannot
+	// be updated and, once every repository is generated, fails with all of
+	// them and with the base images not allowed. Otherwise they are only
+	// logged.
+	Strict bool `yaml:"-"`
 }
 
 type Application struct {
@@ -53,7 +73,101 @@ type Repository struct {
 	PrefetchInput    string   `json:"prefetch-input" yaml:"prefetch-input"`
 	MinVersion       string   `json:"min-version" yaml:"min-version"`
 	MaxVersion       string   `json:"max-version" yaml:"max-version"`
+	BuildPlatforms   []string `json:"build-platforms" yaml:"build-platforms"`
+	// DockerFileOptions override the release ones for the components of the
+	// repository.
+	DockerFileOptions DockerFileOptions `json:"docker-file-options" yaml:"docker-file-options"`
+	// Validation are the rules checked by the release config validation.
+	Validation RepositoryValidation `json:"validation" yaml:"validation"`
 }
+
+// ReleaseBranch returns the upstream branch of a release tag of the
+// repository: release-v1.2.3 when it uses patch branches, release-v1.2.x
+// otherwise.
+func (r Repository) ReleaseBranch(tag string) string {
+	if r.UsePatchBranch {
+		return "release-" + tag
+	}
+	if i := strings.LastIndex(tag, "."); i >= 0 {
+		tag = tag[:i]
+	}
+	return "release-" + tag + ".x"
+}
+
+// RepositoryValidation are the rules the release configs of the numbered
+// releases must follow for a repository.
+type RepositoryValidation struct {
+	// UpstreamBranch is the glob pattern the upstream branch must match, a
+	// template of the Release. For instance, the repositories released along
+	// with the product, such as opc, use "release-v{{ .Version }}.x": the
+	// upstream branch of a release is the one of the same version. The
+	// repositories with their own versions only require a release branch,
+	// e.g. "release-v*.x".
+	UpstreamBranch string `json:"upstream-branch" yaml:"upstream-branch"`
+	// AllowMovingBranch allows a moving branch, e.g. main, as the upstream
+	// branch of the numbered releases within its versions, whatever the
+	// UpstreamBranch rule. It lists the releases which tracked a moving
+	// branch before the upstream repository had release branches, so it
+	// needs a max-version: a later release tracking one is still reported.
+	AllowMovingBranch *VersionRange `json:"allow-moving-branch" yaml:"allow-moving-branch"`
+}
+
+// VersionRange is a range of release versions, both bounds included. An empty
+// bound is not checked.
+type VersionRange struct {
+	MinVersion string `json:"min-version" yaml:"min-version"`
+	MaxVersion string `json:"max-version" yaml:"max-version"`
+}
+
+func (r VersionRange) String() string {
+	if r.MinVersion == "" {
+		return "up to " + r.MaxVersion
+	}
+	return "from " + r.MinVersion + " to " + r.MaxVersion
+}
+
+// Contains reports whether the numbered release version is in the range.
+func (r VersionRange) Contains(version string) bool {
+	v := "v" + version
+	return (r.MinVersion == "" || semver.Compare(v, "v"+r.MinVersion) >= 0) &&
+		(r.MaxVersion == "" || semver.Compare(v, "v"+r.MaxVersion) <= 0)
+}
+
+// movingBranches are the upstream branches which are not bound to a release.
+var movingBranches = []string{"main", "master"}
+
+// Check returns an error when the upstream branch of the release does not
+// follow the rules.
+func (v RepositoryValidation) Check(release Release, upstreamBranch string) error {
+	if !release.IsNumbered() {
+		return nil
+	}
+	if slices.Contains(movingBranches, upstreamBranch) {
+		switch {
+		case v.AllowMovingBranch == nil:
+			return fmt.Errorf("branch %q is a moving branch, set validation.allow-moving-branch to track it in a numbered release", upstreamBranch)
+		case v.AllowMovingBranch.MaxVersion == "":
+			return fmt.Errorf("validation.allow-moving-branch has no max-version")
+		case !v.AllowMovingBranch.Contains(release.Version):
+			return fmt.Errorf("branch %q is a moving branch, validation.allow-moving-branch only allows it %s", upstreamBranch, v.AllowMovingBranch)
+		}
+		return nil
+	}
+	if v.UpstreamBranch == "" {
+		return nil
+	}
+	pattern, err := Eval(v.UpstreamBranch, release)
+	if err != nil {
+		return fmt.Errorf("evaluating upstream-branch rule %q: %w", v.UpstreamBranch, err)
+	}
+	if ok, err := path.Match(pattern, upstreamBranch); err != nil {
+		return fmt.Errorf("invalid upstream-branch rule %q: %w", pattern, err)
+	} else if !ok {
+		return fmt.Errorf("branch %q does not match %q", upstreamBranch, pattern)
+	}
+	return nil
+}
+
 type Branch struct {
 	Name           string
 	UpstreamBranch string `json:"upstream" yaml:"upstream"`
@@ -75,6 +189,16 @@ type Component struct {
 	NoImagePrefix    bool   `json:"no-image-prefix" yaml:"no-image-prefix"`
 	NoImageSuffix    bool   `json:"no-image-suffix" yaml:"no-image-suffix"`
 	NoPrefixUpstream bool   `json:"no-prefix-upstream" yaml:"no-prefix-upstream"`
+	// BuildPlatforms lists the platforms passed to the build pipeline. When
+	// empty, it is inherited from the repository and then from the release.
+	BuildPlatforms []string `json:"build-platforms" yaml:"build-platforms"`
+	// DockerfileTarget is the stage of the Dockerfile built as the component
+	// image, passed to the build pipeline as the target-stage param. When
+	// empty, the last stage is used.
+	DockerfileTarget string `json:"dockerfile-target" yaml:"dockerfile-target"`
+	// DockerFileOptions are merged over the repository and release ones; after
+	// loading, they hold the merged options.
+	DockerFileOptions DockerFileOptions `json:"docker-file-options" yaml:"docker-file-options"`
 }
 
 type Tekton struct {
@@ -101,30 +225,107 @@ type Release struct {
 	ImageSuffix       string            `json:"image-suffix" yaml:"image-suffix"`
 	CodeFreeze        bool              `json:"code-freeze" yaml:"code-freeze"`
 	DockerFileOptions DockerFileOptions `json:"docker-file-options" yaml:"docker-file-options"`
+	BuildPlatforms    []string          `json:"build-platforms" yaml:"build-platforms"`
+	// EOL is the end of life date of the release, as YYYY-MM-DD. Nothing is
+	// generated for the release from that day.
+	EOL string `json:"eol" yaml:"eol"`
+	// Archived releases are not generated anymore and their generated
+	// artifacts are removed.
+	Archived bool `json:"archived" yaml:"archived"`
+	// CodeFreezeExceptions are the downstream repositories still updated
+	// during the code freeze.
+	CodeFreezeExceptions []string `json:"code-freeze-exceptions" yaml:"code-freeze-exceptions"`
 }
 
-func (r Release) FullVersion() string {
-	if slices.Contains(NON_RELEASE_VERSIONS, r.Version) {
-		return r.Version
+// Frozen reports whether the code freeze of the release applies to the
+// downstream repository.
+func (r Release) Frozen(repository string) bool {
+	return r.CodeFreeze && !slices.Contains(r.CodeFreezeExceptions, repository)
+}
+
+// CodeFreezeStatus describes the code freeze of the release for the
+// downstream repository.
+func (r Release) CodeFreezeStatus(repository string) string {
+	switch {
+	case !r.CodeFreeze:
+		return fmt.Sprintf("Code freeze: not active for %s", r.Version)
+	case r.Frozen(repository):
+		return fmt.Sprintf("Code freeze: active for %s", r.Version)
 	}
+	return fmt.Sprintf("Code freeze: active for %s, %s is an exception", r.Version, repository)
+}
 
-	version := r.ReleaseTag
+// EndOfLife reports whether the release is archived or past its end of life
+// date at now.
+func (r Release) EndOfLife(now time.Time) bool {
+	if r.Archived {
+		return true
+	}
+	eol, err := time.Parse(time.DateOnly, r.EOL)
+	return err == nil && !now.Before(eol)
+}
 
-	if !strings.HasPrefix(version, "v") {
-		version = "v" + version
+// IsNumbered reports whether the release is a numbered release, with a
+// release tag, as opposed to main, next or nightly.
+func (r Release) IsNumbered() bool {
+	return !slices.Contains(NON_RELEASE_VERSIONS, r.Version)
+}
+
+// Tag returns the parsed release tag of a numbered release.
+func (r Release) Tag() (ReleaseTag, error) {
+	return ParseReleaseTag(r.ReleaseTag)
+}
+
+// Validate checks the end of life date, and that the release tag of a
+// numbered release is well formed and belongs to the release version.
+func (r Release) Validate() error {
+	if r.EOL != "" {
+		if _, err := time.Parse(time.DateOnly, r.EOL); err != nil {
+			return fmt.Errorf("release %s: invalid eol date %q, expected YYYY-MM-DD", r.Version, r.EOL)
+		}
+	}
+	if !r.IsNumbered() {
+		return nil
 	}
+	tag, err := r.Tag()
+	if err != nil {
+		return fmt.Errorf("release %s: %w", r.Version, err)
+	}
+	if tag.MinorVersion() != r.Version {
+		return fmt.Errorf("release %s: release tag %s belongs to release %s", r.Version, r.ReleaseTag, tag.MinorVersion())
+	}
+	return nil
+}
 
-	return version
+func (r Release) FullVersion() string {
+	if !r.IsNumbered() {
+		return r.Version
+	}
+	tag, err := r.Tag()
+	if err != nil {
+		return "v" + strings.TrimPrefix(r.ReleaseTag, "v")
+	}
+	return "v" + tag.String()
 }
 
 // BaseVersion returns the release tag without the "v" prefix and any "-RC-N"
 // suffix.
 func (r Release) BaseVersion() string {
-	v := r.FullVersion()
-	if idx := strings.Index(v, "-RC-"); idx != -1 {
-		v = v[:idx]
+	if !r.IsNumbered() {
+		return r.Version
 	}
-	return strings.TrimPrefix(v, "v")
+	tag, err := r.Tag()
+	if err != nil {
+		return strings.TrimPrefix(r.ReleaseTag, "v")
+	}
+	return tag.BaseVersion()
+}
+
+// GA reports whether the release tag is the first final release of a numbered
+// release.
+func (r Release) GA() bool {
+	tag, err := r.Tag()
+	return r.IsNumbered() && err == nil && tag.GA()
 }
 
 type ApplicationConfig struct {
@@ -158,7 +359,86 @@ var (
 	}
 )
 
+// DockerFileOptions are applied to the component Dockerfiles. String values
+// are templates evaluated with the component.
 type DockerFileOptions struct {
-	Args   map[string]string `yaml:"args"`
-	Labels any               `yaml:"labels"`
+	Args map[string]string `yaml:"args"`
+	// Labels are added to the generated labels of the image, replacing the
+	// default ones with the same name.
+	Labels map[string]string `yaml:"labels"`
+	// RemoveLabels are default labels which are not set on the image.
+	RemoveLabels []string `yaml:"remove-labels"`
+	// Pins maps images referenced by tag in args to their digest reference.
+	// They are maintained by `konflux -pin-images`.
+	Pins map[string]string `yaml:"pins"`
+	// Summary, Description and DisplayName replace the generated
+	// human-readable labels of the image.
+	Summary     string `yaml:"summary"`
+	Description string `yaml:"description"`
+	DisplayName string `yaml:"display-name"`
+}
+
+// Merge returns the options with the ones set in override taking precedence.
+// Args, labels and pins are merged by name.
+func (o DockerFileOptions) Merge(override DockerFileOptions) DockerFileOptions {
+	merged := o
+	merged.Args = map[string]string{}
+	for name, value := range o.Args {
+		merged.Args[name] = value
+	}
+	for name, value := range override.Args {
+		merged.Args[name] = value
+	}
+	merged.Labels = map[string]string{}
+	for name, value := range o.Labels {
+		merged.Labels[name] = value
+	}
+	merged.Pins = map[string]string{}
+	for image, pinned := range o.Pins {
+		merged.Pins[image] = pinned
+	}
+	for image, pinned := range override.Pins {
+		merged.Pins[image] = pinned
+	}
+	merged.RemoveLabels = slices.Clone(o.RemoveLabels)
+	// Labels removed by override drop the inherited ones, and labels set by
+	// override are no longer removed.
+	for _, name := range override.RemoveLabels {
+		delete(merged.Labels, name)
+		if !slices.Contains(merged.RemoveLabels, name) {
+			merged.RemoveLabels = append(merged.RemoveLabels, name)
+		}
+	}
+	for name, value := range override.Labels {
+		merged.Labels[name] = value
+		merged.RemoveLabels = slices.DeleteFunc(merged.RemoveLabels, func(n string) bool { return n == name })
+	}
+	if override.Summary != "" {
+		merged.Summary = override.Summary
+	}
+	if override.Description != "" {
+		merged.Description = override.Description
+	}
+	if override.DisplayName != "" {
+		merged.DisplayName = override.DisplayName
+	}
+	return merged
+}
+
+// ReadResource reads any type of resource from the YAML file
+// dir/resourceType/resourceName, with the .yaml extension added when missing.
+func ReadResource[T any](dir, resourceType, resourceName string) (T, error) {
+	var result T
+	if !strings.HasSuffix(resourceName, ".yaml") {
+		resourceName += ".yaml"
+	}
+	filePath := filepath.Join(dir, resourceType, resourceName)
+	in, err := os.ReadFile(filePath)
+	if err != nil {
+		return result, err
+	}
+	if err := yaml.UnmarshalStrict(in, &result); err != nil {
+		return result, fmt.Errorf("error while parsing config %s: %w", filePath, err)
+	}
+	return result, nil
 }
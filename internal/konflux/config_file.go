@@ -0,0 +1,166 @@
+package konflux
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is a YAML configuration file edited in place. Comments, key
+// order and the style of the values which are not modified are preserved.
+type ConfigFile struct {
+	path string
+	doc  yaml.Node
+}
+
+func ReadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &ConfigFile{path: path}
+	if err := yaml.Unmarshal(data, &f.doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if f.doc.Kind == 0 {
+		f.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if f.root().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top-level value is not a mapping", path)
+	}
+	return f, nil
+}
+
+func (f *ConfigFile) root() *yaml.Node {
+	return f.doc.Content[0]
+}
+
+// Set sets the scalar at the given keys. The style of an existing value is
+// kept, its tag is resolved again from the new value.
+func (f *ConfigFile) Set(value string, keys ...string) {
+	node := f.ensure(keys...)
+	style := node.Style
+	if node.Kind != yaml.ScalarNode {
+		style = 0
+	}
+	s := scalar(value, style)
+	s.HeadComment, s.LineComment, s.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = *s
+}
+
+// SetMap replaces the mapping at the given keys with values, sorted by key.
+// Comments on the existing entries are kept.
+func (f *ConfigFile) SetMap(values map[string]string, keys ...string) {
+	node := f.ensure(keys...)
+	existing := map[string][2]*yaml.Node{}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			existing[node.Content[i].Value] = [2]*yaml.Node{node.Content[i], node.Content[i+1]}
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: node.HeadComment, LineComment: node.LineComment}
+	for _, name := range names {
+		key, value := &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &yaml.Node{Kind: yaml.ScalarNode}
+		if e, ok := existing[name]; ok {
+			key, value = e[0], e[1]
+		}
+		s := scalar(values[name], value.Style)
+		s.HeadComment, s.LineComment, s.FootComment = value.HeadComment, value.LineComment, value.FootComment
+		mapping.Content = append(mapping.Content, key, s)
+	}
+	*node = *mapping
+}
+
+// MoveToFront moves the given top-level keys, when present, before the other
+// ones, in the given order.
+func (f *ConfigFile) MoveToFront(keys ...string) {
+	root := f.root()
+	var front, rest []*yaml.Node
+	for _, key := range keys {
+		for i := 0; i < len(root.Content); i += 2 {
+			if root.Content[i].Value == key {
+				front = append(front, root.Content[i], root.Content[i+1])
+			}
+		}
+	}
+	for i := 0; i < len(root.Content); i += 2 {
+		if !slices.Contains(keys, root.Content[i].Value) {
+			rest = append(rest, root.Content[i], root.Content[i+1])
+		}
+	}
+	root.Content = append(front, rest...)
+}
+
+// ensure returns the value at the given keys, creating the missing mappings
+// and an empty value.
+func (f *ConfigFile) ensure(keys ...string) *yaml.Node {
+	node := f.root()
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		value := mappingValue(node, key)
+		if value == nil {
+			value = &yaml.Node{Kind: yaml.ScalarNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		}
+		node = value
+	}
+	return node
+}
+
+// Bytes returns the file content with the changes.
+func (f *ConfigFile) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&f.doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the file back.
+func (f *ConfigFile) Write() error {
+	data, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0o644)
+}
+
+// scalar returns the string value as a scalar node of the given style. A
+// plain value which would read back as a number, like the version 1.30, is
+// double-quoted instead.
+func scalar(value string, style yaml.Style) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Style: style, Value: value}
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	return node
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestConfigFileSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		value   string
+		keys    []string
+		want    string
+	}{{
+		name:    "version replacing a string",
+		content: "# Release\nversion: next # moving\n",
+		value:   "1.30",
+		keys:    []string{"version"},
+		want:    "# Release\nversion: \"1.30\" # moving\n",
+	}, {
+		name:    "new version",
+		content: "release-tag: 1.25.0\n",
+		value:   "1.25",
+		keys:    []string{"version"},
+		want:    "release-tag: 1.25.0\nversion: \"1.25\"\n",
+	}, {
+		name:    "single-quoted version",
+		content: "version: '1.24'\n",
+		value:   "1.25",
+		keys:    []string{"version"},
+		want:    "version: '1.25'\n",
+	}, {
+		name:    "release tag",
+		content: "release-tag: 1.25.0-RC-1\n",
+		value:   "1.25.0",
+		keys:    []string{"release-tag"},
+		want:    "release-tag: 1.25.0\n",
+	}, {
+		name:    "boolean",
+		content: "code-freeze: true\n",
+		value:   "false",
+		keys:    []string{"code-freeze"},
+		want:    "code-freeze: false\n",
+	}, {
+		name:    "nested",
+		content: "branches:\n  tektoncd-pipeline:\n    upstream: release-v1.6.x\n",
+		value:   "1.7",
+		keys:    []string{"branches", "tektoncd-pipeline", "upstream"},
+		want:    "branches:\n  tektoncd-pipeline:\n    upstream: \"1.7\"\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := ReadConfigFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Set(tt.value, tt.keys...)
+			if err := f.Write(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("file:\n%s\nwant:\n%s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFileSetMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "pins:\n  # builder\n  golang:1.23: golang@sha256:1\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ReadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetMap(map[string]string{"golang:1.23": "golang@sha256:2", "VERSION": "1.10"}, "pins")
+	data, err := f.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "pins:\n  VERSION: \"1.10\"\n  # builder\n  golang:1.23: golang@sha256:2\n"
+	if string(data) != want {
+		t.Errorf("file:\n%s\nwant:\n%s", data, want)
+	}
+	var pins struct {
+		Pins map[string]string `yaml:"pins"`
+	}
+	if err := yaml.Unmarshal(data, &pins); err != nil {
+		t.Fatal(err)
+	}
+	if pins.Pins["VERSION"] != "1.10" {
+		t.Errorf("VERSION read back = %q, want 1.10", pins.Pins["VERSION"])
+	}
+}
@@ -2,7 +2,9 @@ package konflux
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -25,6 +27,9 @@ func GenerateConfig(application Application, dryRun, generateTekton bool) error
 
 func generateRepositoryConfig(application Application, dryRun bool) error {
 	log.Printf("Generating repository configuration")
+	var failed []error
+	var missing []MissingDockerfile
+	var rejected []RejectedBaseImage
 	for _, repo := range application.Repositories {
 		ctx := context.Background()
 		var dir string
@@ -32,13 +37,42 @@ func generateRepositoryConfig(application Application, dryRun bool) error {
 		if dir, err = cloneAndCheckout(ctx, repo, filepath.Join("/tmp/konflux/", application.Config.Product)); err != nil {
 			return err
 		}
-		if err := cleanupAutogenerated(ctx, application, dir, tektonDir); err != nil {
+		// During the code freeze, the only change pushed is the one of the
+		// GitHub workflows which stops merging upstream changes. Once a
+		// repository has it, nothing is updated until the freeze ends.
+		frozen := application.Release.Frozen(repo.Name)
+		if frozen && (repo.Upstream == "" || workflowsFrozen(dir)) {
+			log.Printf("Code freeze for %s, not updating %s", application.Release.Version, repo.Name)
+			continue
+		}
+		if frozen {
+			log.Printf("Code freeze for %s, only freezing the GitHub workflows of %s", application.Release.Version, repo.Name)
+		} else if err := cleanupAutogenerated(ctx, application, dir, tektonDir); err != nil {
 			return err
 		}
-		if application.Release.Version != "main" {
-			if err := generateTektonConfig(repo, dir); err != nil {
+		if application.Release.Version != "main" && !frozen {
+			repoMissing := missingDockerfiles(repo, dir)
+			missing = append(missing, repoMissing...)
+			err := generateTektonConfig(repo, dir)
+			var dockerfileErr *dockerfileError
+			if err != nil && !errors.As(err, &dockerfileErr) {
 				return err
 			}
+			if application.Config.Strict && (err != nil || len(repoMissing) > 0) {
+				// The missing Dockerfiles are reported once for the whole
+				// application.
+				log.Printf("Not updating %s: Dockerfiles cannot be updated", repo.Name)
+				if err != nil {
+					failed = append(failed, fmt.Errorf("repository %s: %w", repo.Name, err))
+				}
+				continue
+			}
+			if err != nil {
+				log.Printf("Error while updating docker files: %s", err)
+			}
+			if allowed := application.Config.AllowedBaseImages; len(allowed) > 0 {
+				rejected = append(rejected, rejectedBaseImages(repo, dir, allowed)...)
+			}
 		}
 		if err := generateGitHubConfig(repo, dir); err != nil {
 			return err
@@ -50,8 +84,89 @@ func generateRepositoryConfig(application Application, dryRun bool) error {
 			return err
 		}
 	}
-	return nil
+	var reports []error
+	if len(missing) > 0 {
+		reports = append(reports, &MissingDockerfilesError{Application: application.Name, Missing: missing})
+	}
+	if len(rejected) > 0 {
+		reports = append(reports, &RejectedBaseImagesError{Application: application.Name, Rejected: rejected})
+	}
+	for _, err := range reports {
+		if !application.Config.Strict {
+			log.Printf("warning: %s", err)
+		} else {
+			failed = append(failed, err)
+		}
+	}
+	return errors.Join(failed...)
+
+}
+
+// frozenWorkflows are the GitHub workflows merging upstream changes, whose job
+// does not run during the code freeze.
+var frozenWorkflows = []string{"auto-merge-upstream.yaml", "update-sources.yaml"}
+
+// workflowsFrozen reports whether the workflows of the checked out repository
+// already skip merging upstream changes.
+func workflowsFrozen(dir string) bool {
+	for _, name := range frozenWorkflows {
+		data, err := os.ReadFile(filepath.Join(dir, gitHubDir, "workflows", name))
+		if err != nil || !strings.Contains(string(data), "if: false") {
+			return false
+		}
+	}
+	return true
+}
+
+// dockerfileError is returned when the Dockerfiles of a repository cannot be
+// updated.
+type dockerfileError struct {
+	errs []error
+}
+
+func (e *dockerfileError) Error() string {
+	return errors.Join(e.errs...).Error()
+}
 
+func (e *dockerfileError) Unwrap() []error {
+	return e.errs
+}
+
+// MissingDockerfile is a component whose Dockerfile does not exist in the
+// checked out branch of its repository.
+type MissingDockerfile struct {
+	Repository string
+	Component  string
+	Dockerfile string
+	Branch     string
+}
+
+// MissingDockerfilesError reports the components of an application whose
+// Dockerfile does not exist. It fails the generation in strict mode and is
+// only logged otherwise.
+type MissingDockerfilesError struct {
+	Application string
+	Missing     []MissingDockerfile
+}
+
+func (e *MissingDockerfilesError) Error() string {
+	lines := make([]string, 0, len(e.Missing))
+	for _, m := range e.Missing {
+		lines = append(lines, fmt.Sprintf("  %s/%s: %s (branch %s)", m.Repository, m.Component, m.Dockerfile, m.Branch))
+	}
+	return fmt.Sprintf("components of %s with a missing Dockerfile:\n%s", e.Application, strings.Join(lines, "\n"))
+}
+
+// missingDockerfiles returns the components of the repository whose
+// Dockerfile does not exist in the checked out branch.
+func missingDockerfiles(repo Repository, dir string) []MissingDockerfile {
+	var missing []MissingDockerfile
+	for _, c := range repo.Components {
+		if _, err := os.Stat(filepath.Join(dir, c.Dockerfile)); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, MissingDockerfile{Repository: repo.Name, Component: c.Name, Dockerfile: c.Dockerfile, Branch: repo.Branch.Name})
+		}
+	}
+	return missing
 }
 
 func generateTektonConfig(repo Repository, targetDir string) error {
@@ -62,18 +177,22 @@ func generateTektonConfig(repo Repository, targetDir string) error {
 		return err
 	}
 
+	var dockerfileErrs []error
 	for _, c := range repo.Components {
-		v := c.Version
-		if err := generateFileFromTemplate("component-pull-request.yaml", c, filepath.Join(target, fmt.Sprintf("%s-%s-%s-pull-request.yaml", hyphenize(basename(c.Repository.Name)), hyphenize(v.Version), hyphenize(c.Name))), repo.Application); err != nil {
+		if err := generateFileFromTemplate("component-pull-request.yaml", c, filepath.Join(target, fmt.Sprintf("%s-pull-request.yaml", c.KonfluxName())), repo.Application); err != nil {
 			return err
 		}
-		if err := generateFileFromTemplate("component-push.yaml", c, filepath.Join(target, fmt.Sprintf("%s-%s-%s-push.yaml", hyphenize(basename(c.Repository.Name)), hyphenize(v.Version), hyphenize(c.Name))), repo.Application); err != nil {
+		if err := generateFileFromTemplate("component-push.yaml", c, filepath.Join(target, fmt.Sprintf("%s-push.yaml", c.KonfluxName())), repo.Application); err != nil {
 			return err
 		}
-		if err := MutateDockerFile(c, targetDir); err != nil {
-			log.Printf("Error while updating docker files: %s", err)
+		// Missing Dockerfiles are reported by missingDockerfiles.
+		if err := MutateDockerFile(c, targetDir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			dockerfileErrs = append(dockerfileErrs, fmt.Errorf("component %s: %w", c.Name, err))
 		}
 	}
+	if len(dockerfileErrs) > 0 {
+		return &dockerfileError{errs: dockerfileErrs}
+	}
 
 	return nil
 }
@@ -121,6 +240,9 @@ func generateKonfluxConfig(application Application) error {
 	if application.Release.Version == "main" {
 		return nil
 	}
+	if err := application.validateNames(); err != nil {
+		return err
+	}
 	targetDir := filepath.Join(konfluxDir, application.Config.Product, hyphenize(application.Release.Version), application.Name)
 	err := cleanupAutogenerated(context.Background(), application, targetDir)
 	if err != nil {
@@ -152,10 +274,10 @@ func generateKonfluxConfig(application Application) error {
 }
 
 func generateKonfluxApplication(application Application, targetDir string) error {
-	if err := generateFileFromTemplate("application.yaml", application, filepath.Join(targetDir, "application.yaml"), application); err != nil {
+	if err := writeObjects(filepath.Join(targetDir, "application.yaml"), application, newApplicationObject(application)); err != nil {
 		return err
 	}
-	if err := generateFileFromTemplate("tests.yaml", application, filepath.Join(targetDir, "tests.yaml"), application); err != nil {
+	if err := writeObjects(filepath.Join(targetDir, "tests.yaml"), application, newEnterpriseContractTest(application)); err != nil {
 		return err
 	}
 	log.Printf("Create Release Tests in %s\n", targetDir)
@@ -163,24 +285,22 @@ func generateKonfluxApplication(application Application, targetDir string) error
 		for arch, instanceType := range instanceTypes {
 			application.InstanceType = instanceType
 			application.InstanceArch = arch
-			if err := generateFileFromTemplate("release-tests.yaml", application, filepath.Join(targetDir, arch+"-release-tests.yaml"), application); err != nil {
+			if err := writeObjects(filepath.Join(targetDir, arch+"-release-tests.yaml"), application, newReleaseTests(application)); err != nil {
 				return err
 			}
 		}
 
 	}
-	if err := generateFileFromTemplate("release-plan.yaml", application, filepath.Join(targetDir, "release-plan.yaml"), application); err != nil {
+	if err := writeObjects(filepath.Join(targetDir, "release-plan.yaml"), application, newReleasePlan(application)); err != nil {
 		return err
 	}
 	_, err := strconv.ParseFloat(application.Release.Version, 64)
 	if err == nil {
 		rpaTargetDir := filepath.Join(konfluxDir, application.Config.RPADir)
 		cdnProductDir := filepath.Join(konfluxDir, application.Config.CdnProductDir)
-		var templateFile string
+		newAdmission := newImageReleasePlanAdmission
 		if application.ShortName == "fbc" {
-			templateFile = "release-plan-admission-fbc.yaml"
-		} else {
-			templateFile = "release-plan-admission.yaml"
+			newAdmission = newFBCReleasePlanAdmission
 		}
 
 		if application.ShortName == "core" {
@@ -189,28 +309,21 @@ func generateKonfluxApplication(application Application, targetDir string) error
 			}
 		}
 		for env, _ := range releaseEnvironments {
-			rpaFile := fmt.Sprintf("%s-%s-%s-%s.yaml", application.Config.Product, hyphenize(application.Release.Version), application.ShortName, env)
-			rpaCdnFile := fmt.Sprintf("%s-%s-%s-%s-%s.yaml", application.Config.Product, hyphenize(application.Release.Version), application.ShortName, "cdn", env)
-			templateData := struct {
-				Application // Embedded (no field name)
-				Env         string
-			}{
-				Application: application,
-				Env:         env,
-			}
-			if err := generateFileFromTemplate(templateFile, templateData, filepath.Join(rpaTargetDir, rpaFile), application); err != nil {
+			rpaFile := application.ReleasePlanAdmissionName(env) + ".yaml"
+			rpaCdnFile := application.CDNReleasePlanAdmissionName(env) + ".yaml"
+			if err := writeObjects(filepath.Join(rpaTargetDir, rpaFile), application, newAdmission(application, env)); err != nil {
 				return err
 			}
-			releasePlanFile := fmt.Sprintf("%s-%s-%s-%s-rp.yaml", application.Config.Product, hyphenize(application.Release.Version), application.ShortName, env)
-			if err := generateFileFromTemplate("release-plan-managed.yaml", templateData, filepath.Join(targetDir, releasePlanFile), application); err != nil {
+			releasePlanFile := application.ReleasePlanAdmissionName(env) + "-rp.yaml"
+			if err := writeObjects(filepath.Join(targetDir, releasePlanFile), application, newImageReleasePlan(application, env)); err != nil {
 				return err
 			}
 			if application.ShortName == "core" {
-				cdnReleasePlanFile := fmt.Sprintf("%s-%s-%s-%s-%s-rp.yaml", application.Config.Product, hyphenize(application.Release.Version), application.ShortName, "cdn", env)
-				if err := generateFileFromTemplate("release-plan-admission-cdn.yaml", templateData, filepath.Join(rpaTargetDir, rpaCdnFile), application); err != nil {
+				cdnReleasePlanFile := application.CDNReleasePlanAdmissionName(env) + "-rp.yaml"
+				if err := writeObjects(filepath.Join(rpaTargetDir, rpaCdnFile), application, newCDNReleasePlanAdmission(application, env)); err != nil {
 					return err
 				}
-				if err := generateFileFromTemplate("release-plan-cdn.yaml", templateData, filepath.Join(targetDir, cdnReleasePlanFile), application); err != nil {
+				if err := writeObjects(filepath.Join(targetDir, cdnReleasePlanFile), application, newCDNReleasePlan(application, env)); err != nil {
 					return err
 				}
 			}
@@ -224,10 +337,14 @@ func generateKonfluxComponents(application Application, targetDir string) error
 	log.Printf("Generate %s konflux configuration in %s\n", application.Name, targetDir)
 	for _, c := range application.Components {
 		componentDir := filepath.Join(targetDir, c.Repository.Name)
-		if err := generateFileFromTemplate("component.yaml", c, filepath.Join(componentDir, fmt.Sprintf("component-%s-%s.yaml", c.Name, application.Release.Version)), application); err != nil {
+		component, err := newComponentObject(c)
+		if err != nil {
+			return err
+		}
+		if err := writeObjects(filepath.Join(componentDir, fmt.Sprintf("component-%s-%s.yaml", c.Name, application.Release.Version)), application, component); err != nil {
 			return err
 		}
-		if err := generateFileFromTemplate("image.yaml", c, filepath.Join(componentDir, fmt.Sprintf("image-%s-%s.yaml", c.Name, application.Release.Version)), application); err != nil {
+		if err := writeObjects(filepath.Join(componentDir, fmt.Sprintf("image-%s-%s.yaml", c.Name, application.Release.Version)), application, newImageRepositoryObject(c)); err != nil {
 			return err
 		}
 		pyxisDir := getPyxisDir(application)
@@ -0,0 +1,160 @@
+package konflux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMissingDockerfiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "controller"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "controller", "Dockerfile"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo := Repository{
+		Name:   "tektoncd/pipeline",
+		Branch: Branch{Name: "release-v1.3.x"},
+		Components: []Component{
+			{Name: "controller", Dockerfile: "cmd/controller/Dockerfile"},
+			{Name: "webhook", Dockerfile: "cmd/webhook/Dockerfile"},
+		},
+	}
+	missing := missingDockerfiles(repo, dir)
+	want := []MissingDockerfile{{Repository: "tektoncd/pipeline", Component: "webhook", Dockerfile: "cmd/webhook/Dockerfile", Branch: "release-v1.3.x"}}
+	if !reflect.DeepEqual(missing, want) {
+		t.Fatalf("missingDockerfiles() = %+v, want %+v", missing, want)
+	}
+
+	err := errors.Join(fmt.Errorf("repository tektoncd/pipeline: failed"), &MissingDockerfilesError{Application: "openshift-pipelines-core", Missing: missing})
+	var missingErr *MissingDockerfilesError
+	if !errors.As(err, &missingErr) || len(missingErr.Missing) != 1 {
+		t.Fatalf("errors.As(%v) did not find the missing Dockerfiles", err)
+	}
+	wantMsg := "components of openshift-pipelines-core with a missing Dockerfile:\n  tektoncd/pipeline/webhook: cmd/webhook/Dockerfile (branch release-v1.3.x)"
+	if missingErr.Error() != wantMsg {
+		t.Errorf("Error() = %q, want %q", missingErr.Error(), wantMsg)
+	}
+}
+
+func TestWorkflowsFrozen(t *testing.T) {
+	for _, codeFreeze := range []bool{false, true} {
+		dir := t.TempDir()
+		if workflowsFrozen(dir) {
+			t.Fatal("workflowsFrozen() = true without workflows")
+		}
+		release := &Release{Version: "1.23", CodeFreeze: codeFreeze}
+		repo := Repository{
+			Name:        "tektoncd-pipeline",
+			Upstream:    "tektoncd/pipeline",
+			Branch:      Branch{Name: "release-v1.23.x"},
+			Application: Application{Release: release},
+		}
+		if err := generateGitHubConfig(repo, dir); err != nil {
+			t.Fatal(err)
+		}
+		if got := workflowsFrozen(dir); got != codeFreeze {
+			t.Errorf("workflowsFrozen() = %t with the workflows generated for code-freeze %t", got, codeFreeze)
+		}
+	}
+}
+
+// gitRepository creates a git repository with the files committed on the
+// branch, to be cloned by the generator, and returns its path.
+func gitRepository(t *testing.T, branch string, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", branch},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := run(context.Background(), dir, "git", args...); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestGenerateRepositoryConfigStrict(t *testing.T) {
+	const branch = "release-v1.23.x"
+	application := Application{
+		Name:      "openshift-pipelines-core",
+		Namespace: "tekton-ecosystem-tenant",
+		Release:   &Release{Version: "1.23", ReleaseTag: "1.23.0"},
+		Config: Config{
+			Product:           "test-" + strings.ReplaceAll(t.Name(), "/", "-"),
+			Strict:            true,
+			AllowedBaseImages: []string{"registry.access.redhat.com/ubi*/*"},
+		},
+	}
+	t.Cleanup(func() { os.RemoveAll(filepath.Join("/tmp/konflux", application.Config.Product)) })
+	dockerfile := "ARG GO_BUILDER=golang:1.23\nFROM $GO_BUILDER\n"
+	repository := func(name string, files map[string]string, components ...string) Repository {
+		repo := Repository{
+			Name:        name,
+			Upstream:    "tektoncd/" + strings.TrimPrefix(name, "tektoncd-"),
+			Url:         gitRepository(t, branch, files),
+			Branch:      Branch{Name: branch},
+			Application: application,
+		}
+		for _, c := range components {
+			repo.Components = append(repo.Components, Component{
+				Name:        c,
+				Image:       "pipelines-" + c + "-rhel9",
+				Dockerfile:  ".konflux/dockerfiles/" + c + ".Dockerfile",
+				Version:     *application.Release,
+				Application: application,
+				Repository:  repo,
+			})
+		}
+		return repo
+	}
+	application.Repositories = []Repository{
+		repository("tektoncd-chains", map[string]string{".konflux/dockerfiles/controller.Dockerfile": dockerfile}, "controller"),
+		repository("tektoncd-pipeline", map[string]string{".konflux/dockerfiles/controller.Dockerfile": dockerfile}, "controller", "webhook"),
+		repository("tektoncd-triggers", map[string]string{".konflux/dockerfiles/controller.Dockerfile": dockerfile + "FROM quay.io/example/runtime\n"}, "controller"),
+	}
+
+	err := generateRepositoryConfig(application, true)
+	var missingErr *MissingDockerfilesError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("generateRepositoryConfig() = %v, want the missing Dockerfiles", err)
+	}
+	if strings.Count(err.Error(), "webhook.Dockerfile") != 1 {
+		t.Errorf("the missing Dockerfile is not reported once:\n%s", err)
+	}
+	var rejectedErr *RejectedBaseImagesError
+	if !errors.As(err, &rejectedErr) {
+		t.Fatalf("generateRepositoryConfig() = %v, want the rejected base images", err)
+	}
+	want := []RejectedBaseImage{{Repository: "tektoncd-triggers", Component: "controller", Dockerfile: ".konflux/dockerfiles/controller.Dockerfile", Image: "quay.io/example/runtime"}}
+	if !reflect.DeepEqual(rejectedErr.Rejected, want) {
+		t.Errorf("rejected base images = %+v, want %+v", rejectedErr.Rejected, want)
+	}
+	// The repositories before and after the failing one are generated, the
+	// failing one is not updated. Rejected base images are only reported.
+	for _, name := range []string{"tektoncd-chains", "tektoncd-pipeline", "tektoncd-triggers"} {
+		workflow := filepath.Join("/tmp/konflux", application.Config.Product, "1.23", name, gitHubDir, "workflows", "update-sources.yaml")
+		_, err := os.Stat(workflow)
+		if updated := err == nil; updated != (name != "tektoncd-pipeline") {
+			t.Errorf("%s: workflows generated %t", name, updated)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package konflux
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDockerFileOptionsMerge(t *testing.T) {
+	tests := []struct {
+		name                     string
+		release, repo, component DockerFileOptions
+		want                     DockerFileOptions
+	}{{
+		name: "nothing set",
+		want: DockerFileOptions{Args: map[string]string{}, Labels: map[string]string{}, Pins: map[string]string{}},
+	}, {
+		name:      "empty maps keep the inherited values",
+		release:   DockerFileOptions{Args: map[string]string{"GO_BUILDER": "go:1.23"}, Labels: map[string]string{"vendor": "Red Hat"}, Pins: map[string]string{"go:1.23": "go@sha256:1"}},
+		repo:      DockerFileOptions{Args: map[string]string{}, Labels: map[string]string{}, Pins: map[string]string{}},
+		component: DockerFileOptions{RemoveLabels: []string{}},
+		want:      DockerFileOptions{Args: map[string]string{"GO_BUILDER": "go:1.23"}, Labels: map[string]string{"vendor": "Red Hat"}, Pins: map[string]string{"go:1.23": "go@sha256:1"}},
+	}, {
+		name:      "component over repository over release",
+		release:   DockerFileOptions{Args: map[string]string{"GO_BUILDER": "go:1.22", "RUNTIME": "ubi9"}, Summary: "release", Description: "release"},
+		repo:      DockerFileOptions{Args: map[string]string{"GO_BUILDER": "go:1.23"}, Summary: "repository", DisplayName: "repository"},
+		component: DockerFileOptions{Args: map[string]string{"GO_BUILDER": "go:1.24"}, Summary: "component"},
+		want: DockerFileOptions{
+			Args:        map[string]string{"GO_BUILDER": "go:1.24", "RUNTIME": "ubi9"},
+			Labels:      map[string]string{},
+			Pins:        map[string]string{},
+			Summary:     "component",
+			Description: "release",
+			DisplayName: "repository",
+		},
+	}, {
+		name:      "labels and pins merged by name",
+		release:   DockerFileOptions{Labels: map[string]string{"vendor": "Red Hat", "url": "release"}, Pins: map[string]string{"go:1.23": "go@sha256:1"}},
+		repo:      DockerFileOptions{Labels: map[string]string{"url": "repository"}, Pins: map[string]string{"ubi9": "ubi9@sha256:2"}},
+		component: DockerFileOptions{Pins: map[string]string{"go:1.23": "go@sha256:3"}},
+		want: DockerFileOptions{
+			Args:   map[string]string{},
+			Labels: map[string]string{"vendor": "Red Hat", "url": "repository"},
+			Pins:   map[string]string{"go:1.23": "go@sha256:3", "ubi9": "ubi9@sha256:2"},
+		},
+	}, {
+		name:    "override removes an inherited label",
+		release: DockerFileOptions{Labels: map[string]string{"vendor": "Red Hat", "url": "release"}},
+		repo:    DockerFileOptions{RemoveLabels: []string{"url", "release"}},
+		want: DockerFileOptions{
+			Args:         map[string]string{},
+			Labels:       map[string]string{"vendor": "Red Hat"},
+			Pins:         map[string]string{},
+			RemoveLabels: []string{"url", "release"},
+		},
+	}, {
+		name:      "override sets a label removed by the base",
+		release:   DockerFileOptions{RemoveLabels: []string{"io.openshift.tags", "release"}},
+		component: DockerFileOptions{Labels: map[string]string{"io.openshift.tags": "tekton"}},
+		want: DockerFileOptions{
+			Args:         map[string]string{},
+			Labels:       map[string]string{"io.openshift.tags": "tekton"},
+			Pins:         map[string]string{},
+			RemoveLabels: []string{"release"},
+		},
+	}, {
+		name:      "removed and set again down the chain",
+		release:   DockerFileOptions{Labels: map[string]string{"url": "release"}},
+		repo:      DockerFileOptions{RemoveLabels: []string{"url"}},
+		component: DockerFileOptions{Labels: map[string]string{"url": "component"}},
+		want: DockerFileOptions{
+			Args:         map[string]string{},
+			Labels:       map[string]string{"url": "component"},
+			Pins:         map[string]string{},
+			RemoveLabels: []string{},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := tt.release
+			releaseLabels := len(release.Labels)
+			got := release.Merge(tt.repo).Merge(tt.component)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+			if len(release.Labels) != releaseLabels {
+				t.Errorf("Merge() modified the labels of the release: %v", release.Labels)
+			}
+		})
+	}
+}
+
+func TestReleaseEndOfLife(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		release Release
+		eol     bool
+		wantErr bool
+	}{
+		{name: "no end of life", release: Release{Version: "1.23", ReleaseTag: "1.23.0"}},
+		{name: "before the end of life", release: Release{Version: "1.23", ReleaseTag: "1.23.0", EOL: "2026-03-02"}},
+		{name: "end of life day", release: Release{Version: "1.23", ReleaseTag: "1.23.0", EOL: "2026-03-01"}, eol: true},
+		{name: "past the end of life", release: Release{Version: "1.15", ReleaseTag: "1.15.4", EOL: "2025-01-01"}, eol: true},
+		{name: "archived", release: Release{Version: "1.15", ReleaseTag: "1.15.4", Archived: true}, eol: true},
+		{name: "malformed date", release: Release{Version: "1.23", ReleaseTag: "1.23.0", EOL: "01/03/2026"}, wantErr: true},
+		{name: "date and time", release: Release{Version: "1.23", ReleaseTag: "1.23.0", EOL: "2026-03-01T00:00:00Z"}, wantErr: true},
+		{name: "invalid day", release: Release{Version: "1.23", ReleaseTag: "1.23.0", EOL: "2026-02-30"}, wantErr: true},
+		{name: "malformed date of an unnumbered release", release: Release{Version: "next", ReleaseTag: "next", EOL: "soon"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.release.EndOfLife(now); got != tt.eol {
+				t.Errorf("EndOfLife() = %t, want %t", got, tt.eol)
+			}
+			err := tt.release.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "invalid eol date") {
+				t.Errorf("Validate() = %v, want an invalid eol date error", err)
+			}
+		})
+	}
+}
+
+func TestRepositoryValidationCheck(t *testing.T) {
+	sameVersion := RepositoryValidation{UpstreamBranch: "release-v{{ .Version }}.x"}
+	releaseBranch := RepositoryValidation{UpstreamBranch: "release-v*.x"}
+	movingBranch := RepositoryValidation{UpstreamBranch: "release-v*.x", AllowMovingBranch: &VersionRange{MinVersion: "1.21", MaxVersion: "1.23"}}
+	tests := []struct {
+		name       string
+		validation RepositoryValidation
+		version    string
+		branch     string
+		wantErr    string
+	}{
+		{name: "same version", validation: sameVersion, version: "1.23", branch: "release-v1.23.x"},
+		{name: "other version", validation: sameVersion, version: "1.23", branch: "release-v1.22.x", wantErr: `does not match "release-v1.23.x"`},
+		{name: "next is not checked", validation: sameVersion, version: "next", branch: "release-v1.22.x"},
+		{name: "minor pattern", validation: RepositoryValidation{UpstreamBranch: "release-v1.*.x"}, version: "1.21", branch: "release-v1.23.x"},
+		{name: "minor pattern of another major", validation: RepositoryValidation{UpstreamBranch: "release-v1.*.x"}, version: "1.21", branch: "release-v0.1.x", wantErr: "does not match"},
+		{name: "release branch", validation: releaseBranch, version: "1.24", branch: "release-v0.1.x"},
+		{name: "patch branch", validation: releaseBranch, version: "1.24", branch: "release-v0.9.0", wantErr: "does not match"},
+		{name: "moving branch", validation: releaseBranch, version: "1.23", branch: "main", wantErr: "is a moving branch"},
+		{name: "moving branch without rule", version: "1.23", branch: "master", wantErr: "is a moving branch"},
+		{name: "allowed moving branch", validation: movingBranch, version: "1.23", branch: "main"},
+		{name: "moving branch allowed in earlier releases", validation: movingBranch, version: "1.24", branch: "main", wantErr: "only allows it from 1.21 to 1.23"},
+		{name: "moving branch allowed in later releases", validation: movingBranch, version: "1.20", branch: "main", wantErr: "only allows it from 1.21 to 1.23"},
+		{name: "release branch with an allowed moving branch", validation: movingBranch, version: "1.24", branch: "release-v1.7.x"},
+		{name: "moving branch allowed in every release", validation: RepositoryValidation{AllowMovingBranch: &VersionRange{MinVersion: "1.21"}}, version: "1.30", branch: "main", wantErr: "has no max-version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validation.Check(Release{Version: tt.version}, tt.branch)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Check() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
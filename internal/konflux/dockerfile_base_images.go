@@ -0,0 +1,150 @@
+package konflux
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+// baseImage is an image a stage is built from, as written after FROM and
+// with the ARGs substituted.
+type baseImage struct {
+	From, Image string
+}
+
+// baseImages returns the images the stages are built from, with the ARGs
+// declared before the first FROM substituted. args override the defaults
+// of the declared ARGs, as MutateDockerFile does. Stages built from a
+// previous stage or from scratch are skipped.
+func (d *dockerfile) baseImages(args map[string]string) ([]baseImage, error) {
+	lex := shell.NewLex(d.escapeToken)
+	var env []string
+	for _, node := range d.args {
+		for _, word := range nodeValues(node) {
+			name, value, _ := strings.Cut(word, "=")
+			if v, ok := args[name]; ok {
+				value = v
+			} else if value != "" {
+				processed, _, err := lex.ProcessWord(value, shell.EnvsFromSlice(env))
+				if err != nil {
+					return nil, fmt.Errorf("ARG %s: %w", name, err)
+				}
+				value = processed
+			}
+			env = append(env, name+"="+value)
+		}
+	}
+
+	var images []baseImage
+	stages := map[string]bool{"scratch": true}
+	for _, stage := range d.stages {
+		values := nodeValues(stage.From)
+		if len(values) == 0 {
+			continue
+		}
+		image, _, err := lex.ProcessWord(values[0], shell.EnvsFromSlice(env))
+		if err != nil {
+			return nil, fmt.Errorf("FROM %s: %w", values[0], err)
+		}
+		if !stages[strings.ToLower(image)] {
+			images = append(images, baseImage{From: values[0], Image: image})
+		}
+		if stage.Name != "" {
+			stages[strings.ToLower(stage.Name)] = true
+		}
+	}
+	return images, nil
+}
+
+// RejectedBaseImage is a base image of a component Dockerfile whose
+// repository is not allowed.
+type RejectedBaseImage struct {
+	Repository string
+	Component  string
+	Dockerfile string
+	// Image is the base image, with the ARGs substituted, or the FROM
+	// argument followed by "(unresolved)" when an ARG is not declared.
+	Image string
+}
+
+// RejectedBaseImagesError reports the base images of an application which
+// match none of the allowed-base-images patterns. It fails the generation in
+// strict mode and is only logged otherwise.
+type RejectedBaseImagesError struct {
+	Application string
+	Rejected    []RejectedBaseImage
+}
+
+func (e *RejectedBaseImagesError) Error() string {
+	lines := make([]string, 0, len(e.Rejected))
+	for _, r := range e.Rejected {
+		lines = append(lines, fmt.Sprintf("  %s/%s: %s: %s", r.Repository, r.Component, r.Dockerfile, r.Image))
+	}
+	return fmt.Sprintf("base images of %s not allowed:\n%s", e.Application, strings.Join(lines, "\n"))
+}
+
+// rejectedBaseImages checks the base images of the Dockerfiles of the
+// repository checked out in dir against the allowed patterns, once they are
+// updated. Missing Dockerfiles, and the ones which cannot be parsed, are
+// reported when updating them and skipped.
+func rejectedBaseImages(repo Repository, dir string, allowed []string) []RejectedBaseImage {
+	var rejected []RejectedBaseImage
+	for _, c := range repo.Components {
+		d, err := readDockerfile(filepath.Join(dir, c.Dockerfile))
+		if err != nil {
+			continue
+		}
+		args, err := getArgs(c)
+		if err != nil {
+			continue
+		}
+		images, err := d.baseImages(args)
+		if err != nil {
+			log.Printf("Cannot check the base images of %s: %s", d.path, err)
+			continue
+		}
+		for _, image := range notAllowedImages(images, allowed) {
+			rejected = append(rejected, RejectedBaseImage{Repository: repo.Name, Component: c.Name, Dockerfile: c.Dockerfile, Image: image})
+		}
+	}
+	return rejected
+}
+
+// notAllowedImages returns the sorted base images whose repository matches
+// none of the allowed patterns.
+func notAllowedImages(images []baseImage, allowed []string) []string {
+	var rejected []string
+	for _, image := range images {
+		if image.Image == "" {
+			rejected = append(rejected, fmt.Sprintf("%s (unresolved)", image.From))
+			continue
+		}
+		if !allowedImage(image.Image, allowed) {
+			rejected = append(rejected, image.Image)
+		}
+	}
+	sort.Strings(rejected)
+	return rejected
+}
+
+// allowedImage reports whether the repository of the image matches one of
+// the patterns, as understood by path.Match.
+func allowedImage(image string, patterns []string) bool {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return false
+	}
+	repository := ref.Context().Name()
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, repository); ok {
+			return true
+		}
+	}
+	return false
+}
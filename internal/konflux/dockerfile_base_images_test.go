@@ -0,0 +1,168 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestBaseImages(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		args       map[string]string
+		want       []baseImage
+	}{{
+		name:       "global ARG default",
+		dockerfile: "ARG RUNTIME=registry.access.redhat.com/ubi9/ubi-minimal:latest\nFROM $RUNTIME\n",
+		want:       []baseImage{{From: "$RUNTIME", Image: "registry.access.redhat.com/ubi9/ubi-minimal:latest"}},
+	}, {
+		name:       "ARG default using a previous ARG",
+		dockerfile: "ARG REGISTRY=registry.redhat.io\nARG RUNTIME=${REGISTRY}/ubi9/ubi:9.4\nFROM ${RUNTIME}\n",
+		want:       []baseImage{{From: "${RUNTIME}", Image: "registry.redhat.io/ubi9/ubi:9.4"}},
+	}, {
+		name:       "ARG overridden",
+		dockerfile: "ARG GO_BUILDER=golang:1.23\nFROM $GO_BUILDER\n",
+		args:       map[string]string{"GO_BUILDER": "registry.access.redhat.com/ubi9/go-toolset:1.23"},
+		want:       []baseImage{{From: "$GO_BUILDER", Image: "registry.access.redhat.com/ubi9/go-toolset:1.23"}},
+	}, {
+		name:       "ARG without default",
+		dockerfile: "ARG GO_BUILDER\nFROM $GO_BUILDER\n",
+		args:       map[string]string{"GO_BUILDER": "registry.access.redhat.com/ubi9/go-toolset:1.23"},
+		want:       []baseImage{{From: "$GO_BUILDER", Image: "registry.access.redhat.com/ubi9/go-toolset:1.23"}},
+	}, {
+		name:       "ARG declared in a stage",
+		dockerfile: "FROM registry.access.redhat.com/ubi9/ubi-minimal AS base\nARG RUNTIME=quay.io/example/runtime\nFROM $RUNTIME\n",
+		want:       []baseImage{{From: "registry.access.redhat.com/ubi9/ubi-minimal", Image: "registry.access.redhat.com/ubi9/ubi-minimal"}, {From: "$RUNTIME"}},
+	}, {
+		name:       "previous stages",
+		dockerfile: "FROM golang:1.23 AS builder\nFROM Builder AS test\nFROM test\n",
+		want:       []baseImage{{From: "golang:1.23", Image: "golang:1.23"}},
+	}, {
+		name:       "stage referenced before it is declared",
+		dockerfile: "FROM builder\nFROM golang:1.23 AS builder\n",
+		want:       []baseImage{{From: "builder", Image: "builder"}, {From: "golang:1.23", Image: "golang:1.23"}},
+	}, {
+		name:       "scratch",
+		dockerfile: "FROM golang:1.23 AS builder\nFROM scratch\n",
+		want:       []baseImage{{From: "golang:1.23", Image: "golang:1.23"}},
+	}, {
+		name:       "platform flag",
+		dockerfile: "ARG GO_BUILDER=golang:1.23\nFROM --platform=$BUILDPLATFORM $GO_BUILDER AS builder\nFROM --platform=linux/amd64 registry.redhat.io/ubi9/ubi-minimal\n",
+		want:       []baseImage{{From: "$GO_BUILDER", Image: "golang:1.23"}, {From: "registry.redhat.io/ubi9/ubi-minimal", Image: "registry.redhat.io/ubi9/ubi-minimal"}},
+	}, {
+		name:       "escape directive",
+		dockerfile: "# escape=`\nARG RUNTIME=registry.redhat.io/ubi9/ubi\nFROM $RUNTIME\n",
+		want:       []baseImage{{From: "$RUNTIME", Image: "registry.redhat.io/ubi9/ubi"}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseDockerfile("Dockerfile", []byte(tt.dockerfile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := d.baseImages(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("baseImages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseImagesPinned(t *testing.T) {
+	const pinned = "registry.access.redhat.com/ubi9/go-toolset@sha256:0123456789012345678901234567890123456789012345678901234567890123"
+	c := dockerfileComponent("Dockerfile")
+	c.DockerFileOptions.Pins = map[string]string{"registry.access.redhat.com/ubi9/go-toolset:1.23": pinned}
+	args, err := getArgs(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := parseDockerfile("Dockerfile", []byte("ARG GO_BUILDER=golang:1.23\nFROM $GO_BUILDER\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, err := d.baseImages(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 || images[0].Image != pinned {
+		t.Errorf("baseImages() = %+v, want %s", images, pinned)
+	}
+}
+
+// TestAllowedBaseImages checks images against the allowed-base-images of
+// the downstream configuration.
+func TestAllowedBaseImages(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "downstream", "konflux.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.AllowedBaseImages) == 0 {
+		t.Fatal("no allowed-base-images in the downstream configuration")
+	}
+	tests := []struct {
+		image   string
+		allowed bool
+	}{
+		{"registry.access.redhat.com/ubi9/ubi-minimal:latest", true},
+		{"registry.access.redhat.com/ubi8/go-toolset@sha256:0123456789012345678901234567890123456789012345678901234567890123", true},
+		{"registry.access.redhat.com/ubi9:latest", true},
+		{"registry.redhat.io/ubi9/ubi-minimal", true},
+		{"registry.redhat.io/ubi9", true},
+		{"registry.redhat.io/rhel9/go-toolset:1.23", true},
+		{"registry.redhat.io/openshift4/ose-cli-rhel9:v4.16", true},
+		{"golang:1.23", false},
+		{"docker.io/library/ubi9:latest", false},
+		{"quay.io/openshift-pipelines/ubi9/ubi-minimal", false},
+		{"brew.registry.redhat.io/rh-osbs/openshift-golang-builder:v1.23", false},
+		{"registry.redhat.io/openshift4/ose-cli/extra", false},
+		{"registry.redhat.io.example.com/ubi9/ubi", false},
+		{"registry.access.redhat.com/ubi9/ubi/minimal", false},
+		{"not a reference", false},
+	}
+	for _, tt := range tests {
+		if got := allowedImage(tt.image, config.AllowedBaseImages); got != tt.allowed {
+			t.Errorf("allowedImage(%s) = %t, want %t", tt.image, got, tt.allowed)
+		}
+	}
+}
+
+func TestRejectedBaseImages(t *testing.T) {
+	dir := t.TempDir()
+	dockerfiles := map[string]string{
+		"controller.Dockerfile": "ARG GO_BUILDER=golang:1.23\nFROM $GO_BUILDER AS builder\nFROM quay.io/example/runtime:latest\nCOPY --from=builder /app /app\n",
+		"webhook.Dockerfile":    "FROM $UNDECLARED\n",
+		"cli.Dockerfile":        "FROM registry.access.redhat.com/ubi9/ubi-minimal\n",
+	}
+	for name, content := range dockerfiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := Repository{Name: "tektoncd-pipeline"}
+	for _, name := range []string{"cli", "controller", "missing", "webhook"} {
+		c := dockerfileComponent(name + ".Dockerfile")
+		c.Name = name
+		repo.Components = append(repo.Components, c)
+	}
+	allowed := []string{"registry.access.redhat.com/ubi*/*"}
+	got := rejectedBaseImages(repo, dir, allowed)
+	want := []RejectedBaseImage{
+		{Repository: "tektoncd-pipeline", Component: "controller", Dockerfile: "controller.Dockerfile", Image: "quay.io/example/runtime:latest"},
+		{Repository: "tektoncd-pipeline", Component: "webhook", Dockerfile: "webhook.Dockerfile", Image: "$UNDECLARED (unresolved)"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("rejectedBaseImages() = %+v, want %+v", got, want)
+	}
+}
@@ -1,202 +1,506 @@
 package konflux
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
 )
 
-func MutateDockerFile(component Component, repoDir string) error {
+// dockerfile is a parsed Dockerfile. The original lines are kept so that only
+// the instructions that need to change are rewritten, leaving comments and
+// formatting untouched everywhere else.
+type dockerfile struct {
+	path  string
+	lines []string
+	// args are the ARG instructions declared before the first FROM.
+	args   []*parser.Node
+	stages []dockerfileStage
+	edits  []dockerfileEdit
+	// escapeToken is the escape character set by the parser directive, used
+	// for line continuations and in quoted words.
+	escapeToken rune
+	// newline is the line ending of the file, "\n" or "\r\n".
+	newline string
+}
 
-	newArgs := getArgs(component)
-	newLabels := getDockerFileLabels(component)
+// dockerfileStage is a build stage, from its FROM instruction up to the next
+// one.
+type dockerfileStage struct {
+	Name         string
+	From         *parser.Node
+	Instructions []*parser.Node
+}
 
-	dockerfile := filepath.Join(repoDir, component.Dockerfile)
-	data, err := os.ReadFile(dockerfile)
+// dockerfileEdit replaces the lines [start, end) of the Dockerfile.
+type dockerfileEdit struct {
+	start, end int
+	lines      []string
+}
+
+func readDockerfile(path string) (*dockerfile, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return parseDockerfile(path, data)
+}
 
-	lines := strings.Split(string(data), "\n")
+func parseDockerfile(path string, data []byte) (*dockerfile, error) {
+	result, err := parser.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	d := &dockerfile{path: path, escapeToken: result.EscapeToken, newline: "\n"}
+	content := string(data)
+	if first, _, ok := strings.Cut(content, "\n"); ok && strings.HasSuffix(first, "\r") {
+		d.newline = "\r\n"
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	d.lines = strings.Split(content, "\n")
+	for _, node := range result.AST.Children {
+		switch {
+		case isCommand(node, command.From):
+			stage := dockerfileStage{From: node}
+			if args := nodeValues(node); len(args) == 3 && strings.EqualFold(args[1], "as") {
+				stage.Name = args[2]
+			}
+			d.stages = append(d.stages, stage)
+		case len(d.stages) == 0:
+			if isCommand(node, command.Arg) {
+				d.args = append(d.args, node)
+			}
+		default:
+			last := &d.stages[len(d.stages)-1]
+			last.Instructions = append(last.Instructions, node)
+		}
+	}
+	return d, nil
+}
 
-	labels := map[string]string{}
+// instructions returns the instructions of the given command, global ones
+// first and then stage by stage.
+func (d *dockerfile) instructions(cmd string) []*parser.Node {
+	var nodes []*parser.Node
+	for _, node := range d.args {
+		if isCommand(node, cmd) {
+			nodes = append(nodes, node)
+		}
+	}
+	for _, stage := range d.stages {
+		for _, node := range stage.Instructions {
+			if isCommand(node, cmd) {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
 
-	var labelStart = -1
-	var labelEnd = -1
+// replace rewrites the lines of the instruction, unless they already match.
+func (d *dockerfile) replace(node *parser.Node, lines []string) {
+	start, end := node.StartLine-1, node.EndLine
+	if slices.Equal(d.lines[start:end], lines) {
+		return
+	}
+	d.edits = append(d.edits, dockerfileEdit{start: start, end: end, lines: lines})
+}
 
-	for i := range lines {
+// insert adds lines before the given zero-based line index.
+func (d *dockerfile) insert(line int, lines []string) {
+	d.edits = append(d.edits, dockerfileEdit{start: line, end: line, lines: lines})
+}
 
-		trim := strings.TrimSpace(lines[i])
+// render returns the Dockerfile with the edits applied, with the line ending
+// of the original file.
+func (d *dockerfile) render() []byte {
+	edits := slices.Clone(d.edits)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	lines := d.lines
+	for _, edit := range edits {
+		lines = slices.Concat(lines[:edit.start], edit.lines, lines[edit.end:])
+	}
+	return []byte(strings.Join(lines, d.newline))
+}
 
-		// ------------------
-		// Update ARG
-		// ------------------
-		if strings.HasPrefix(trim, "ARG ") {
+// isCommand reports whether the instruction is the given command. The parser
+// only lowercases the command to dispatch it: node.Value keeps the case of the
+// Dockerfile, so that "from" and "FROM" are both found.
+func isCommand(node *parser.Node, cmd string) bool {
+	return strings.EqualFold(node.Value, cmd)
+}
 
-			arg := strings.TrimPrefix(trim, "ARG ")
-			parts := strings.SplitN(arg, "=", 2)
+// nodeValues returns the words of an instruction.
+func nodeValues(node *parser.Node) []string {
+	var values []string
+	for n := node.Next; n != nil; n = n.Next {
+		values = append(values, n.Value)
+	}
+	return values
+}
 
-			if len(parts) == 2 {
+// indentation returns the leading whitespace of the first line of the
+// instruction.
+func (d *dockerfile) indentation(node *parser.Node) string {
+	line := d.lines[node.StartLine-1]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
 
-				name := strings.TrimSpace(parts[0])
+func MutateDockerFile(component Component, repoDir string) error {
+	path := filepath.Join(repoDir, component.Dockerfile)
+	d, err := readDockerfile(path)
+	if err != nil {
+		return err
+	}
+	target, err := d.stage(component.DockerfileTarget)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	args, err := getArgs(component)
+	if err != nil {
+		return fmt.Errorf("%s: %w", component.Name, err)
+	}
+	newLabels, err := getDockerFileLabels(component)
+	if err != nil {
+		return fmt.Errorf("%s: %w", component.Name, err)
+	}
+	d.updateArgs(args)
+	d.updateLabels(target, newLabels, component.DockerFileOptions.RemoveLabels)
+	// The Dockerfile is only written once the result is validated, so that a
+	// rejected Dockerfile is left as it was.
+	data := d.render()
+	if err := validateDockerfileLabels(path, data, component.DockerfileTarget, newLabels); err != nil {
+		return err
+	}
+	if len(d.edits) == 0 {
+		return nil
+	}
+	return os.WriteFile(path, data, 0o644)
+}
 
-				if v, ok := newArgs[name]; ok {
-					lines[i] = fmt.Sprintf("ARG %s=%s", name, v)
-				}
-			}
+// stage returns the stage with the given name, or the last stage if name is
+// empty.
+func (d *dockerfile) stage(name string) (*dockerfileStage, error) {
+	if len(d.stages) == 0 {
+		return nil, fmt.Errorf("no FROM instruction")
+	}
+	if name == "" {
+		return &d.stages[len(d.stages)-1], nil
+	}
+	for i := range d.stages {
+		if strings.EqualFold(d.stages[i].Name, name) {
+			return &d.stages[i], nil
 		}
+	}
+	return nil, fmt.Errorf("target stage %q not found", name)
+}
 
-		// ------------------
-		// Detect LABEL block
-		// ------------------
-		if strings.HasPrefix(trim, "LABEL") {
-
-			if labelStart == -1 {
-				labelStart = i
+// imageLabels returns the labels of the image built from the stage, including
+// the ones inherited from the stages it is based on.
+func (d *dockerfile) imageLabels(stage *dockerfileStage) map[string]string {
+	labels := map[string]string{}
+	if values := nodeValues(stage.From); len(values) > 0 {
+		for i := range d.stages {
+			parent := &d.stages[i]
+			if parent == stage {
+				break
 			}
-
-			labelEnd = i
-
-			line := trim
-
-			for strings.HasSuffix(line, "\\") {
-
-				labelEnd++
-
-				if labelEnd >= len(lines) {
-					break
-				}
-
-				line = strings.TrimSpace(lines[labelEnd])
+			if parent.Name != "" && strings.EqualFold(parent.Name, values[0]) {
+				labels = d.imageLabels(parent)
 			}
 		}
 	}
+	for _, node := range stage.Instructions {
+		if isCommand(node, command.Label) {
+			for _, l := range d.dockerfileLabels(node) {
+				labels[l.key] = l.value
+			}
+		}
+	}
+	return labels
+}
 
-	// ------------------
-	// Extract label values
-	// ------------------
-	if labelStart >= 0 {
-
-		for _, line := range lines[labelStart : labelEnd+1] {
-
-			l := strings.TrimSpace(line)
-			l = strings.TrimPrefix(l, "LABEL")
-			l = strings.TrimSuffix(l, "\\")
-			l = strings.TrimSpace(l)
+// validateDockerfileLabels checks that the Dockerfile parses and that the image
+// built from its target stage carries every required label.
+func validateDockerfileLabels(path string, data []byte, target string, required map[string]string) error {
+	d, err := parseDockerfile(path, data)
+	if err != nil {
+		return err
+	}
+	stage, err := d.stage(target)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	labels := d.imageLabels(stage)
+	var missing []string
+	for k := range required {
+		if _, ok := labels[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%s: image is missing required labels %s", path, strings.Join(missing, ", "))
+	}
+	return nil
+}
 
-			if l == "" {
+// updateArgs sets the default value of the ARG instructions declaring one of
+// the given arguments. Only the changed words are rewritten, so that the
+// keyword case and the line continuations of the instruction are kept.
+func (d *dockerfile) updateArgs(args map[string]string) {
+	for _, node := range d.instructions(command.Arg) {
+		lines := slices.Clone(d.lines[node.StartLine-1 : node.EndLine])
+		words := nodeValues(node)
+		inPlace := true
+		for i, word := range words {
+			name, _, _ := strings.Cut(word, "=")
+			value, ok := args[name]
+			if !ok {
 				continue
 			}
+			words[i] = name + "=" + d.quoteArgValue(value)
+			if inPlace && words[i] != word {
+				inPlace = replaceWord(lines, word, words[i])
+			}
+		}
+		if !inPlace {
+			// A word split over several lines is rewritten with the whole
+			// instruction on one line.
+			keyword := strings.Fields(lines[0])[0]
+			lines = []string{d.indentation(node) + keyword + " " + strings.Join(words, " ")}
+		}
+		d.replace(node, lines)
+	}
+}
 
-			parts := strings.SplitN(l, "=", 2)
+// replaceWord replaces the first occurrence of the whitespace separated word
+// in the lines of an instruction, and reports whether it was found.
+func replaceWord(lines []string, word, replacement string) bool {
+	for i, line := range lines {
+		for offset := 0; ; {
+			index := strings.Index(line[offset:], word)
+			if index < 0 {
+				break
+			}
+			start, end := offset+index, offset+index+len(word)
+			if (start == 0 || isBlank(line[start-1])) && (end == len(line) || isBlank(line[end])) {
+				lines[i] = line[:start] + replacement + line[end:]
+				return true
+			}
+			offset = end
+		}
+	}
+	return false
+}
 
-			if len(parts) == 2 {
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
 
-				k := strings.TrimSpace(parts[0])
-				v := strings.Trim(parts[1], `"`)
+// dockerfileLabel is a label as written in the Dockerfile: the value is kept
+// quoted and escaped.
+type dockerfileLabel struct {
+	key, value string
+}
 
-				if k != "" {
-					labels[k] = v
-				}
-			}
+// dockerfileLabels returns the labels set by a LABEL instruction. Each label is a key
+// node followed by its value and separator nodes.
+func (d *dockerfile) dockerfileLabels(node *parser.Node) []dockerfileLabel {
+	var result []dockerfileLabel
+	for key := node.Next; key != nil && key.Next != nil && key.Next.Next != nil; key = key.Next.Next.Next {
+		value := key.Next.Value
+		// The legacy "LABEL key value" form takes the rest of the line,
+		// unquoted, as the value.
+		if key.Next.Next.Value == "" && !isQuoted(value) {
+			value = d.quote(value)
 		}
+		result = append(result, dockerfileLabel{key: strings.Trim(key.Value, `"'`), value: value})
 	}
+	return result
+}
 
-	// ------------------
-	// Merge labels
-	// ------------------
+// updateLabels merges the given labels with the ones already set in the
+// stage, without the removed ones, and writes them as a single sorted LABEL
+// instruction in place of the first one. Labels of the other stages are left
+// untouched.
+func (d *dockerfile) updateLabels(stage *dockerfileStage, newLabels map[string]string, removed []string) {
+	var nodes []*parser.Node
+	for _, node := range stage.Instructions {
+		if isCommand(node, command.Label) {
+			nodes = append(nodes, node)
+		}
+	}
+	merged := map[string]string{}
+	for _, node := range nodes {
+		for _, l := range d.dockerfileLabels(node) {
+			merged[l.key] = l.value
+		}
+	}
+	for _, k := range removed {
+		delete(merged, k)
+	}
 	for k, v := range newLabels {
-		labels[k] = v
+		merged[k] = d.quote(v)
+	}
+	if len(merged) == 0 {
+		for _, node := range nodes {
+			d.replace(node, nil)
+		}
+		return
+	}
+	if len(nodes) == 0 {
+		d.insert(d.stageEnd(stage), append([]string{""}, d.labelBlock("", merged)...))
+		return
 	}
+	d.replace(nodes[0], d.labelBlock(d.indentation(nodes[0]), merged))
+	for _, node := range nodes[1:] {
+		d.replace(node, nil)
+	}
+}
 
-	// ------------------
-	// Sort labels
-	// ------------------
-	keys := make([]string, 0, len(labels))
+// stageEnd returns the index of the line following the last instruction of
+// the stage.
+func (d *dockerfile) stageEnd(stage *dockerfileStage) int {
+	last := stage.From
+	if len(stage.Instructions) > 0 {
+		last = stage.Instructions[len(stage.Instructions)-1]
+	}
+	return last.EndLine
+}
 
+// labelBlock formats labels as a LABEL instruction with one label per line,
+// continued with the escape token of the Dockerfile.
+func (d *dockerfile) labelBlock(indent string, labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
 	for k := range labels {
 		keys = append(keys, k)
 	}
-
 	sort.Strings(keys)
 
-	// ------------------
-	// Build LABEL block
-	// ------------------
-	var labelBlock []string
-
-	labelBlock = append(labelBlock, "LABEL \\")
-
+	continuation := " " + string(d.escapeToken)
+	block := []string{indent + "LABEL" + continuation}
 	for i, k := range keys {
-
-		v := labels[k]
-
-		if i == len(keys)-1 {
-			labelBlock = append(labelBlock,
-				fmt.Sprintf("    %s=\"%s\"", k, v))
-		} else {
-			labelBlock = append(labelBlock,
-				fmt.Sprintf("    %s=\"%s\" \\", k, v))
+		line := fmt.Sprintf("%s    %s=%s", indent, d.quoteLabelKey(k), labels[k])
+		if i < len(keys)-1 {
+			line += continuation
 		}
+		block = append(block, line)
 	}
+	return block
+}
 
-	// ------------------
-	// Replace LABEL block
-	// ------------------
-	var result []string
-
-	if labelStart >= 0 {
-
-		result = append(result, lines[:labelStart]...)
-		result = append(result, labelBlock...)
-		result = append(result, lines[labelEnd+1:]...)
+func isQuoted(word string) bool {
+	return len(word) >= 2 && (word[0] == '"' || word[0] == '\'') && word[len(word)-1] == word[0]
+}
 
-	} else {
+// quote returns value as a double-quoted Dockerfile word, escaped with the
+// escape token of the Dockerfile.
+func (d *dockerfile) quote(value string) string {
+	escape := string(d.escapeToken)
+	escaper := strings.NewReplacer(escape, escape+escape, `"`, escape+`"`, `$`, escape+`$`)
+	return `"` + escaper.Replace(value) + `"`
+}
 
-		result = append(lines, "")
-		result = append(result, labelBlock...)
+// quoteLabelKey quotes a label key only if it cannot be written as is.
+func (d *dockerfile) quoteLabelKey(key string) string {
+	if strings.ContainsAny(key, " \t\"'=$\\`") {
+		return d.quote(key)
 	}
+	return key
+}
 
-	// ------------------
-	// Write file
-	// ------------------
-	return os.WriteFile(dockerfile, []byte(strings.Join(result, "\n")), 0644)
+// quoteArgValue quotes an ARG default value only if it cannot be written as
+// is, so that image references stay readable.
+func (d *dockerfile) quoteArgValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"'$\\`") {
+		return d.quote(value)
+	}
+	return value
 }
-func getArgs(component Component) map[string]string {
+
+func getArgs(component Component) (map[string]string, error) {
 	// Define Default Args
 	args := map[string]string{
 		"GO_BUILDER": "registry.access.redhat.com/ubi9/go-toolset:latest",
 		"VERSION":    component.Version.Version,
 	}
-	// Get Release Args
-	releaseArgs := component.Application.Release.DockerFileOptions.Args
 
-	// Override default args with release args
-	for name, value := range releaseArgs {
-		args[name] = value
+	// Override default args with the release, repository and component args
+	for name, value := range component.DockerFileOptions.Args {
+		v, err := Eval(value, component)
+		if err != nil {
+			return nil, fmt.Errorf("arg %s: %w", name, err)
+		}
+		args[name] = v
+	}
+
+	// Use the digest of pinned images
+	for name, value := range args {
+		if pinned, ok := component.DockerFileOptions.Pins[value]; ok {
+			args[name] = pinned
+		}
 	}
 
 	// Return final args map
-	return args
+	return args, nil
 }
 
-func getDockerFileLabels(component Component) map[string]string {
+func getDockerFileLabels(component Component) (map[string]string, error) {
+	options := component.DockerFileOptions
+	defaultDescription := fmt.Sprintf("Red Hat OpenShift Pipelines %s %s", component.Repository.Name, component.Name)
+	summary, err := evalOrDefault(options.Summary, defaultDescription, component)
+	if err != nil {
+		return nil, fmt.Errorf("summary: %w", err)
+	}
+	description, err := evalOrDefault(options.Description, defaultDescription, component)
+	if err != nil {
+		return nil, fmt.Errorf("description: %w", err)
+	}
+	displayName, err := evalOrDefault(options.DisplayName, defaultDescription, component)
+	if err != nil {
+		return nil, fmt.Errorf("display-name: %w", err)
+	}
+
 	// Define your dynamic updates here
 	labels := map[string]string{
 		"com.redhat.component": fmt.Sprintf("openshift-%s-container", component.Image),
 		"name":                 fmt.Sprintf("openshift-pipelines/%s", component.Image),
 		"version":              component.Version.FullVersion(),
 		"maintainer":           "pipelines-extcomm@redhat.com",
-		"summary":              fmt.Sprintf("Red Hat OpenShift Pipelines %s %s", component.Repository.Name, component.Name),
-		"description":          fmt.Sprintf("Red Hat OpenShift Pipelines %s %s", component.Repository.Name, component.Name),
-		"io.k8s.description":   fmt.Sprintf("Red Hat OpenShift Pipelines %s %s", component.Repository.Name, component.Name),
-		"io.k8s.display-name":  fmt.Sprintf("Red Hat OpenShift Pipelines %s %s", component.Repository.Name, component.Name),
+		"summary":              summary,
+		"description":          description,
+		"io.k8s.description":   description,
+		"io.k8s.display-name":  displayName,
 		"io.openshift.tags":    fmt.Sprintf("tekton,openshift,%s,%s", component.Repository.Name, component.Name),
 		"cpe":                  fmt.Sprintf("cpe:/a:redhat:openshift_pipelines:%s::%s", component.Version.Version, strings.TrimPrefix(component.Version.ImageSuffix, "-rh")),
 		// Add any others here...
 	}
+	for _, name := range options.RemoveLabels {
+		delete(labels, name)
+	}
+	for name, value := range options.Labels {
+		v, err := Eval(value, component)
+		if err != nil {
+			return nil, fmt.Errorf("label %s: %w", name, err)
+		}
+		labels[name] = v
+	}
 
-	return labels
+	return labels, nil
+}
+
+// evalOrDefault evaluates the template with the component, or returns
+// defaultValue if the template is empty.
+func evalOrDefault(template, defaultValue string, component Component) (string, error) {
+	if template == "" {
+		return defaultValue, nil
+	}
+	return Eval(template, component)
 }
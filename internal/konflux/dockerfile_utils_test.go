@@ -0,0 +1,149 @@
+package konflux
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func dockerfileComponent(dockerfile string) Component {
+	return Component{
+		Name:       "controller",
+		Image:      "pipelines-controller-rhel9",
+		Dockerfile: dockerfile,
+		Version:    Release{Version: "1.23", ReleaseTag: "1.23.0", ImageSuffix: "-rhel9"},
+		Repository: Repository{Name: "tektoncd/pipeline"},
+		DockerFileOptions: DockerFileOptions{
+			Args:         map[string]string{"GO_BUILDER": "registry.access.redhat.com/ubi9/go-toolset:1.23"},
+			Description:  `The "controller" of {{ .Repository.Name }}, built with $GO_BUILDER`,
+			RemoveLabels: []string{"io.openshift.tags", "release"},
+		},
+	}
+}
+
+// TestMutateDockerFile updates the Dockerfiles of testdata/dockerfiles and
+// compares them with their golden files. Run with -update to regenerate them.
+func TestMutateDockerFile(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "dockerfiles", "*.Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no Dockerfile in testdata/dockerfiles")
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".Dockerfile")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := MutateDockerFile(dockerfileComponent("Dockerfile"), dir); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := strings.TrimSuffix(input, ".Dockerfile") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Dockerfile differs from %s:\n%s", golden, got)
+			}
+			if bytes.Contains(data, []byte("\r\n")) && bytes.Count(got, []byte("\n")) != bytes.Count(got, []byte("\r\n")) {
+				t.Error("Dockerfile has mixed line endings")
+			}
+
+			// Updating the Dockerfile again leaves it as it is.
+			if err := MutateDockerFile(dockerfileComponent("Dockerfile"), dir); err != nil {
+				t.Fatal(err)
+			}
+			again, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again, got) {
+				t.Errorf("second update changed the Dockerfile:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestMutateDockerFileNotWrittenOnError(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("ARG GO_BUILDER=golang:1.23\nFROM $GO_BUILDER\nUSER 65532\n")
+	path := filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	component := dockerfileComponent("Dockerfile")
+	component.DockerfileTarget = "runtime"
+	err := MutateDockerFile(component, dir)
+	if err == nil {
+		t.Fatal("MutateDockerFile() = nil, want a target stage error")
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("rejected Dockerfile was written:\n%s", got)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		escape rune
+		value  string
+		want   string
+	}{
+		{'\\', `a "b" $c \d`, `"a \"b\" \$c \\d"`},
+		{'`', `a "b" $c \d`, "\"a `\"b`\" `$c \\d\""},
+		{'`', "a `b`", "\"a ``b``\""},
+	}
+	for _, tt := range tests {
+		d := &dockerfile{escapeToken: tt.escape}
+		if got := d.quote(tt.value); got != tt.want {
+			t.Errorf("quote(%q) with escape %q = %s, want %s", tt.value, tt.escape, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceWord(t *testing.T) {
+	tests := []struct {
+		lines []string
+		word  string
+		want  []string
+		found bool
+	}{
+		{[]string{"ARG BA=1 A=1"}, "A=1", []string{"ARG BA=1 A=2"}, true},
+		{[]string{"ARG A=1 \\", "\tB=1"}, "B=1", []string{"ARG A=1 \\", "\tB=2"}, true},
+		{[]string{"ARG A=10"}, "A=1", []string{"ARG A=10"}, false},
+	}
+	for _, tt := range tests {
+		lines := slices.Clone(tt.lines)
+		replacement := strings.TrimSuffix(tt.word, "1") + "2"
+		if found := replaceWord(lines, tt.word, replacement); found != tt.found || !slices.Equal(lines, tt.want) {
+			t.Errorf("replaceWord(%q, %q) = %t, %q, want %t, %q", tt.lines, tt.word, found, lines, tt.found, tt.want)
+		}
+	}
+}
@@ -130,7 +130,7 @@ func commitAndPullRequest(ctx context.Context, repo Repository, dir string) erro
 			"--head", head,
 			"--label=hack", "--label=automated",
 			"--title", fmt.Sprintf("[bot:%s] update konflux configuration", head),
-			"--body", "This PR was automatically generated by the konflux command from openshift-pipelines/hack repository"+metadataTrailer()); err != nil {
+			"--body", "This PR was automatically generated by the konflux command from openshift-pipelines/hack repository\n\n"+repo.Application.Release.CodeFreezeStatus(repo.Name)+metadataTrailer()); err != nil {
 			return fmt.Errorf("failed to create the pr: %s, %s", err, out)
 		}
 	} else {
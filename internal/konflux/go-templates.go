@@ -3,7 +3,7 @@ package konflux
 import (
 	"bytes"
 	"embed"
-	"log"
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -19,13 +19,33 @@ var templateFS embed.FS
 
 var nameFieldInvalidCharPattern = regexp.MustCompile("[^a-z0-9]")
 
+const userWorkloadsRegistry = "quay.io/redhat-user-workloads"
+
+// templateFuncs returns the functions available to every template rendered by
+// this package, including the nested templates expanded with eval. On top of
+// the Sprig functions it provides:
+//
+//   - hyphenize: replaces any character invalid in a resource name with "-"
+//   - basename: returns the last element of a slash separated path
+//   - indent: prefixes every line of a string with the given number of spaces
+//   - eval: renders a template string against the given data
+//   - imageRef: the user workloads image reference of a Component for a tag
+func templateFuncs() template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+	funcMap["hyphenize"] = hyphenize
+	funcMap["basename"] = basename
+	funcMap["indent"] = indent
+	funcMap["eval"] = Eval
+	funcMap["imageRef"] = imageRef
+	return funcMap
+}
+
+// Eval renders a template string of the configuration against data. Unlike in
+// the templates, contains takes its arguments in the strings.Contains order,
+// contains "string" "substring", as the configuration strings always did.
 func Eval(tmpl string, data interface{}) (string, error) {
-	funcMap := template.FuncMap{
-		"hyphenize": hyphenize,
-		"basename":  basename,
-		"indent":    indent,
-		"contains":  strings.Contains,
-	}
+	funcMap := templateFuncs()
+	funcMap["contains"] = strings.Contains
 	t, err := template.New("inner").Funcs(funcMap).Parse(tmpl)
 	if err != nil {
 		return "", err
@@ -38,48 +58,34 @@ func Eval(tmpl string, data interface{}) (string, error) {
 	return buf.String(), nil
 }
 func generateFileFromTemplate(templateFile string, data interface{}, filePath string, application Application) error {
-	funcMap := template.FuncMap{
-		"hyphenize": hyphenize,
-		"basename":  basename,
-		"indent":    indent,
-		"contains":  strings.Contains,
-		"eval":      Eval,
-	}
-	// merge Sprig funcs into your map
-	for k, v := range sprig.FuncMap() {
-		funcMap[k] = v
-	}
-	tmpl, err := template.New(templateFile).Funcs(funcMap).ParseFS(templateFS, "templates/*/*.yaml", "templates/*/*/*.yaml")
+	tmpl, err := template.New(templateFile).Funcs(templateFuncs()).ParseFS(templateFS, "templates/*/*.yaml", "templates/*/*/*.yaml")
 	if err != nil {
 		return err
 	}
-	parentDir := filepath.Dir(filePath)
-	err = os.MkdirAll(parentDir, os.ModePerm)
-	if err != nil {
-		log.Fatal("Error creating directory:", parentDir, err)
-		return err
-	}
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 
 	// Add AutoGenerated Header
 	header, err := Eval(autoGeneratedHeader, application)
 	if err != nil {
 		return err
 	}
-	if _, err := file.WriteString(header + "\n"); err != nil {
-		return err
-	}
+	var buf bytes.Buffer
+	buf.WriteString(header + "\n")
 
-	err = tmpl.Execute(file, data)
+	err = tmpl.Execute(&buf, data)
 	if err != nil {
 		return err
 	}
-	return nil
+	if err := validateYAML(buf.Bytes(), application.Config.ValidateKinds); err != nil {
+		return fmt.Errorf("template %s rendered for %s: %w", templateFile, dataIdentity(data), err)
+	}
+
+	parentDir := filepath.Dir(filePath)
+	if err := os.MkdirAll(parentDir, os.ModePerm); err != nil {
+		return fmt.Errorf("creating directory %s: %w", parentDir, err)
+	}
+	return os.WriteFile(filePath, buf.Bytes(), 0o644)
 }
+
 func hyphenize(str string) string {
 	return nameFieldInvalidCharPattern.ReplaceAllString(str, "-")
 }
@@ -92,3 +98,7 @@ func indent(spaces int, v string) string {
 	pad := strings.Repeat(" ", spaces)
 	return pad + strings.Replace(v, "\n", "\n"+pad, -1)
 }
+
+func imageRef(c Component, tag string) string {
+	return fmt.Sprintf("%s/%s/%s:%s", userWorkloadsRegistry, c.Application.Namespace, c.Image, tag)
+}
@@ -0,0 +1,199 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestEval(t *testing.T) {
+	component := Component{
+		Name:        "controller",
+		Image:       "pipelines-controller-rhel9",
+		Application: Application{Namespace: "tekton-ecosystem-tenant"},
+		Repository:  Repository{Name: "tektoncd/pipeline"},
+	}
+	tests := []struct {
+		name string
+		tmpl string
+		data interface{}
+		want string
+	}{{
+		name: "contains in the strings.Contains order",
+		tmpl: `{{ if contains "operator-index" "index" }}index{{ else }}image{{ end }}`,
+		want: "index",
+	}, {
+		name: "contains does not use the sprig order",
+		tmpl: `{{ if contains "index" "operator-index" }}index{{ else }}image{{ end }}`,
+		want: "image",
+	}, {
+		name: "hyphenize",
+		tmpl: `{{ hyphenize "tektoncd/pipeline_1.23" }}`,
+		want: "tektoncd-pipeline-1-23",
+	}, {
+		name: "basename",
+		tmpl: `{{ basename .Repository.Name }}`,
+		data: component,
+		want: "pipeline",
+	}, {
+		name: "indent",
+		tmpl: `{{ indent 2 "a\nb" }}`,
+		want: "  a\n  b",
+	}, {
+		name: "sprig",
+		tmpl: `{{ "1.23" | replace "." "-" | upper }}`,
+		want: "1-23",
+	}, {
+		name: "imageRef",
+		tmpl: `{{ imageRef . "on-pr-{{revision}}" }}`,
+		data: component,
+		want: "quay.io/redhat-user-workloads/tekton-ecosystem-tenant/pipelines-controller-rhel9:on-pr-{{revision}}",
+	}, {
+		name: "nested eval",
+		tmpl: `{{ eval "{{ basename .Repository.Name }}" . }}`,
+		data: component,
+		want: "pipeline",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Eval(tt.tmpl, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncsContains(t *testing.T) {
+	// The templates use the Sprig contains, contains "substring" "string".
+	contains, ok := templateFuncs()["contains"].(func(string, string) bool)
+	if !ok {
+		t.Fatal("contains is not a func(string, string) bool")
+	}
+	if !contains("index", "operator-index") {
+		t.Error(`contains "index" "operator-index" = false, want true`)
+	}
+}
+
+// pipelineRunComponent returns a component with the fields used by the
+// PipelineRun templates.
+func pipelineRunComponent(name string) Component {
+	release := &Release{Version: "1.23", ReleaseTag: "1.23.0"}
+	application := Application{Name: "openshift-pipelines-core", Namespace: "tekton-ecosystem-tenant", Release: release}
+	return Component{
+		Name:        name,
+		Image:       "pipelines-" + name + "-rhel9",
+		Dockerfile:  ".konflux/dockerfiles/" + name + ".Dockerfile",
+		Version:     *release,
+		Application: application,
+		Repository: Repository{
+			Name:        "operator",
+			Upstream:    "tektoncd/operator",
+			Url:         "https://github.com/openshift-pipelines/operator",
+			Branch:      Branch{Name: "release-v1.23.x"},
+			Application: application,
+		},
+	}
+}
+
+// pipelineRunParams renders the PipelineRun template for the component and
+// returns its params, keyed by name.
+func pipelineRunParams(t *testing.T, templateFile string, c Component) map[string]interface{} {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pipelinerun.yaml")
+	if err := generateFileFromTemplate(templateFile, c, path, c.Application); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pipelineRun struct {
+		Spec struct {
+			Params []struct {
+				Name  string      `yaml:"name"`
+				Value interface{} `yaml:"value"`
+			} `yaml:"params"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(data, &pipelineRun); err != nil {
+		t.Fatalf("%s: %v\n%s", templateFile, err, data)
+	}
+	params := map[string]interface{}{}
+	for _, param := range pipelineRun.Spec.Params {
+		params[param.Name] = param.Value
+	}
+	return params
+}
+
+func TestPipelineRunBuildPlatforms(t *testing.T) {
+	tests := []struct {
+		name      string
+		component string
+		platforms []string
+		// The build-platforms params of the PipelineRuns, nil when not set.
+		pullRequest, push []interface{}
+	}{{
+		name:        "defaults",
+		component:   "controller",
+		pullRequest: []interface{}{"linux/x86_64"},
+	}, {
+		name:        "operator bundle",
+		component:   "bundle",
+		platforms:   []string{"linux/x86_64"},
+		pullRequest: []interface{}{"linux/x86_64"},
+		push:        []interface{}{"linux/x86_64"},
+	}, {
+		name:        "configured platforms",
+		component:   "controller",
+		platforms:   []string{"linux/x86_64", "linux/arm64"},
+		pullRequest: []interface{}{"linux/x86_64", "linux/arm64"},
+		push:        []interface{}{"linux/x86_64", "linux/arm64"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := pipelineRunComponent(tt.component)
+			c.BuildPlatforms = tt.platforms
+			for templateFile, want := range map[string][]interface{}{
+				"component-pull-request.yaml": tt.pullRequest,
+				"component-push.yaml":         tt.push,
+			} {
+				got, ok := pipelineRunParams(t, templateFile, c)["build-platforms"]
+				if want == nil {
+					if ok {
+						t.Errorf("%s: build-platforms = %v, want none", templateFile, got)
+					}
+					continue
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: build-platforms = %v, want %v", templateFile, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineRunTargetStage(t *testing.T) {
+	for _, target := range []string{"", "runtime"} {
+		c := pipelineRunComponent("controller")
+		c.DockerfileTarget = target
+		for _, templateFile := range []string{"component-pull-request.yaml", "component-push.yaml"} {
+			got, ok := pipelineRunParams(t, templateFile, c)["target-stage"]
+			if target == "" {
+				if ok {
+					t.Errorf("%s: target-stage = %v without a target", templateFile, got)
+				}
+				continue
+			}
+			if got != target {
+				t.Errorf("%s: target-stage = %v, want %s", templateFile, got, target)
+			}
+		}
+	}
+}
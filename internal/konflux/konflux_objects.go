@@ -0,0 +1,375 @@
+package konflux
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	releaseCatalogURL = "https://github.com/konflux-ci/release-service-catalog.git"
+	releaseTenant     = "rhtap-releng-tenant"
+	productName       = "Red Hat OpenShift Pipelines"
+	productID         = 604
+	documentationURL  = "https://docs.redhat.com/en/documentation/red_hat_openshift_pipelines"
+	releaseSolution   = `Red Hat OpenShift Pipelines is a cloud-native, continuous integration and
+continuous delivery (CI/CD) solution based on Kubernetes resources.
+It uses Tekton building blocks to automate deployments across multiple
+platforms by abstracting away the underlying implementation details.
+Tekton introduces a number of standard custom resource definitions (CRDs)
+for defining CI/CD pipelines that are portable across Kubernetes distributions.
+`
+)
+
+func newApplicationObject(a Application) *KonfluxApplication {
+	return &KonfluxApplication{
+		ObjectHeader: newObjectHeader(appstudioV1alpha1, "Application", a.KonfluxName()),
+		Spec:         ApplicationSpec{DisplayName: a.KonfluxName()},
+	}
+}
+
+func newComponentObject(c Component) (*KonfluxComponent, error) {
+	obj := &KonfluxComponent{
+		ObjectHeader: newObjectHeader(appstudioV1alpha1, "Component", c.KonfluxName()),
+		Spec: ComponentSpec{
+			ComponentName:  hyphenize(c.Name),
+			Application:    c.Application.KonfluxName(),
+			BuildNudgesRef: []string{},
+			Source: ComponentSource{Git: GitSource{
+				URL:           c.Repository.Url,
+				DockerfileURL: c.Dockerfile,
+				Revision:      c.Repository.Branch.Name,
+			}},
+		},
+	}
+	obj.Metadata.Annotations = map[string]string{
+		"build.appstudio.openshift.io/request":  "configure-pac-no-mr",
+		"build.appstudio.openshift.io/pipeline": `{"name":"docker-build-multi-platform-oci-ta","bundle":"latest"}`,
+	}
+	if len(c.Nudges) == 0 {
+		obj.Spec.BuildNudgesRef = append(obj.Spec.BuildNudgesRef, "operator-"+hyphenize(c.Version.Version)+"-bundle")
+	}
+	// An empty nudge is used to disable the default bundle nudge.
+	for _, nudge := range c.Nudges {
+		ref, err := Eval(nudge, c)
+		if err != nil {
+			return nil, err
+		}
+		if ref != "" {
+			obj.Spec.BuildNudgesRef = append(obj.Spec.BuildNudgesRef, ref)
+		}
+	}
+	return obj, nil
+}
+
+func newImageRepositoryObject(c Component) *ImageRepository {
+	obj := &ImageRepository{
+		ObjectHeader: newObjectHeader(appstudioV1alpha1, "ImageRepository", c.KonfluxName()),
+		Spec: ImageRepositorySpec{
+			Image: ImageSpec{Name: c.Image, Visibility: "public"},
+			Notifications: []Notification{{
+				Config: NotificationConfig{URL: "https://bombino.api.redhat.com/v1/sbom/quay/push"},
+				Event:  "repo_push",
+				Method: "webhook",
+				Title:  "SBOM-event-to-Bombino",
+			}},
+		},
+	}
+	obj.Metadata.Annotations = map[string]string{
+		"image-controller.appstudio.redhat.com/update-component-image":   "true",
+		"image-controller.appstudio.redhat.com/skip-repository-deletion": "true",
+	}
+	obj.Metadata.Labels = map[string]string{
+		"appstudio.redhat.com/component":   c.KonfluxName(),
+		"appstudio.redhat.com/application": c.Application.KonfluxName(),
+	}
+	return obj
+}
+
+func newIntegrationTestScenario(a Application, name string, context TestContext, params []Param, resolverRef PipelineRef) *IntegrationTestScenario {
+	obj := &IntegrationTestScenario{
+		ObjectHeader: newObjectHeader(appstudioV1beta2, "IntegrationTestScenario", name),
+		Spec: IntegrationTestScenarioSpec{
+			Application: a.KonfluxName(),
+			Contexts:    []TestContext{context},
+			Params:      params,
+			ResolverRef: resolverRef,
+		},
+	}
+	obj.Metadata.Labels = map[string]string{"test.appstudio.openshift.io/optional": "true"}
+	return obj
+}
+
+func newEnterpriseContractTest(a Application) *IntegrationTestScenario {
+	policy := "tekton-ecosystem-tenant/tekton-ecosystem-tenant-containers"
+	if strings.Contains(a.Name, "index") {
+		policy = "tekton-ecosystem-tenant/tekton-ecosystem-tenant-indexes"
+	}
+	return newIntegrationTestScenario(a, a.ResourceName("enterprise-contract"),
+		TestContext{Description: "execute the integration test for a component", Name: "component"},
+		[]Param{
+			{Name: "POLICY_CONFIGURATION", Value: policy},
+			{Name: "TIMEOUT", Value: "15m0s"},
+			{Name: "SINGLE_COMPONENT", Value: "true"},
+		},
+		gitPipelineRef("https://github.com/konflux-ci/build-definitions", "main", "pipelines/enterprise-contract.yaml"))
+}
+
+func newReleaseTests(a Application) *IntegrationTestScenario {
+	testsBranch := "release-v" + a.Release.Version
+	if a.Release.Version == "next" || a.Release.Version == "main" {
+		testsBranch = "master"
+	}
+	return newIntegrationTestScenario(a, a.ResourceName("release-tests-"+a.InstanceArch),
+		TestContext{Description: "execute the integration test for a Snapshot created for a `push` event", Name: "push"},
+		[]Param{
+			{Name: "INSTANCE_TYPE", Value: a.InstanceType},
+			{Name: "GIT_RELEASE_TESTS_BRANCH", Value: testsBranch},
+		},
+		gitPipelineRef("https://github.com/openshift-pipelines/operator", "main", ".konflux/tekton/release-test-pipeline.yaml"))
+}
+
+func newReleasePlan(a Application) *ReleasePlan {
+	obj := &ReleasePlan{
+		ObjectHeader: newObjectHeader(appstudioV1alpha1, "ReleasePlan", a.ReleasePlanName()),
+		Spec: ReleasePlanSpec{
+			Application: a.KonfluxName(),
+			TenantPipeline: &ReleasePipeline{
+				ServiceAccountName: "konflux-bot-0",
+				PipelineRef:        gitPipelineRef("https://github.com/openshift-pipelines/hack.git", "main", "pipelines/release-pipeline.yaml"),
+				Params: []Param{
+					{Name: "release_version", Value: a.Release.FullVersion()},
+					{Name: "release_to_github", Value: strconv.FormatBool(a.ReleaseToGitHub)},
+				},
+			},
+		},
+	}
+	obj.Metadata.Labels = map[string]string{
+		"release.appstudio.openshift.io/auto-release":         strconv.FormatBool(a.AutoRelease),
+		"release.appstudio.openshift.io/standing-attribution": "true",
+	}
+	return obj
+}
+
+// releaseNotes returns the release notes shared by the managed release plans.
+func releaseNotes(a Application) *ReleaseNotes {
+	version := strings.TrimPrefix(a.Release.FullVersion(), "v")
+	return &ReleaseNotes{
+		References:  []string{documentationURL},
+		Type:        "RHEA",
+		Solution:    releaseSolution,
+		Description: "The " + version + " release of Red Hat OpenShift Pipelines Operator.",
+		Topic: "The " + version + " GA release of Red Hat OpenShift Pipelines Operator..\n" +
+			"For more details see [product documentation](" + documentationURL + ").\n",
+		Synopsis: "Red Hat OpenShift Pipelines Release " + version,
+	}
+}
+
+func newManagedReleasePlan(a Application, name, admission string, data *ReleaseData) *ReleasePlan {
+	obj := &ReleasePlan{
+		ObjectHeader: newObjectHeader(appstudioV1alpha1, "ReleasePlan", name),
+		Spec: ReleasePlanSpec{
+			Application: a.KonfluxName(),
+			Target:      releaseTenant,
+			Data:        data,
+		},
+	}
+	obj.Metadata.Labels = map[string]string{
+		"release.appstudio.openshift.io/auto-release":         "false",
+		"release.appstudio.openshift.io/standing-attribution": "true",
+		"release.appstudio.openshift.io/releasePlanAdmission": admission,
+	}
+	return obj
+}
+
+func newImageReleasePlan(a Application, env string) *ReleasePlan {
+	obj := newManagedReleasePlan(a, a.ResourceName(env+"-rp"), a.ReleasePlanAdmissionName(env), &ReleaseData{
+		Mapping: &Mapping{Defaults: MappingDefaults{Tags: []string{
+			a.Release.FullVersion(),
+			a.Release.FullVersion() + "-{{ timestamp }}",
+		}}},
+		ReleaseNotes: releaseNotes(a),
+	})
+	if a.ShortName == "core" {
+		version := strings.TrimPrefix(a.Release.FullVersion(), "v")
+		obj.Spec.Collectors = &Collectors{
+			ServiceAccountName: "tekton-ecosystem-collectors-sa",
+			Secrets:            []string{"jira-collectors-secret"},
+			Items: []CollectorItem{{
+				Name: "jira-collector",
+				Params: []Param{
+					{Name: "url", Value: "https://issues.redhat.com"},
+					{Name: "query", Value: `project = SRVKP AND fixVersion = "Pipelines ` + version + `" AND component NOT IN (QA, Performance, p12n, Operator, "Tekton CLI", "OpenShift OPC") AND (status = "Release Pending" OR (status = Closed AND resolution = Done)) AND (labels is EMPTY OR labels != "release-testing-bug") AND (issuetype = Epic OR ((issuetype IN (Story, Bug)) AND "Epic Link" is EMPTY))`},
+					{Name: "secretName", Value: "jira-collectors-secret"},
+				},
+				Timeout: 120,
+				Type:    "jira",
+			}},
+		}
+	}
+	return obj
+}
+
+func newCDNReleasePlan(a Application, env string) *ReleasePlan {
+	return newManagedReleasePlan(a, a.CDNReleasePlanName(env), a.CDNReleasePlanAdmissionName(env), &ReleaseData{
+		Mapping: &Mapping{Defaults: MappingDefaults{
+			ContentGateway: &ContentGateway{ProductVersionName: a.Release.BaseVersion()},
+		}},
+		ReleaseNotes: releaseNotes(a),
+	})
+}
+
+func newReleasePlanAdmission(a Application, name string, spec ReleasePlanAdmissionSpec) *ReleasePlanAdmission {
+	obj := &ReleasePlanAdmission{
+		ObjectHeader: newObjectHeader(appstudioV1alpha1, "ReleasePlanAdmission", name),
+		Spec:         spec,
+	}
+	obj.Metadata.Namespace = releaseTenant
+	obj.Metadata.Labels = map[string]string{
+		"release.appstudio.openshift.io/block-releases": "false",
+		"pp.engineering.redhat.com/business-unit":       "application-developer",
+	}
+	obj.Spec.Applications = []string{a.KonfluxName()}
+	return obj
+}
+
+func newImageReleasePlanAdmission(a Application, env string) *ReleasePlanAdmission {
+	intention, serviceAccountName, registry := "production", "release-registry-prod", "registry.redhat.io/openshift-pipelines"
+	if env != "prod" {
+		intention, serviceAccountName, registry = "staging", "release-registry-staging", "registry.stage.redhat.io/openshift-pipelines"
+	}
+	mapping := &Mapping{Defaults: MappingDefaults{
+		Tags: []string{
+			"{{ git_sha }}",
+			"{{ git_short_sha }}",
+			a.Release.Version + "-{{ timestamp }}",
+		},
+		PushSourceContainer: true,
+	}}
+	for _, c := range a.Components {
+		mapping.Components = append(mapping.Components, MappingComponent{
+			Name:                c.KonfluxName(),
+			Repositories:        []MappingRepository{{URL: registry + "/" + c.Image}},
+			PushSourceContainer: true,
+		})
+	}
+	obj := newReleasePlanAdmission(a, a.ReleasePlanAdmissionName(env), ReleasePlanAdmissionSpec{
+		Origin: a.Config.Namespace,
+		Policy: "registry-tekton-ecosystem-" + env,
+		Data: ReleaseData{
+			ReleaseNotes: &ReleaseNotes{
+				ProductID:      []int{productID},
+				ProductName:    productName,
+				ProductVersion: a.Release.Version,
+			},
+			Mapping:                             mapping,
+			Intention:                           intention,
+			EnforceContainerFirstSecurityLabels: true,
+		},
+		Pipeline: ReleasePipeline{
+			ServiceAccountName: serviceAccountName,
+			Timeouts:           &Timeouts{Pipeline: "10h0m0s", Tasks: "10h0m0s"},
+			PipelineRef:        gitPipelineRef(releaseCatalogURL, "production", "pipelines/managed/rh-advisories/rh-advisories.yaml"),
+		},
+	})
+	obj.Metadata.Annotations = map[string]string{"rhel_target": strings.TrimPrefix(a.Release.ImageSuffix, "-rh")}
+	return obj
+}
+
+func newFBCReleasePlanAdmission(a Application, env string) *ReleasePlanAdmission {
+	fbc := &FBCData{
+		StagedIndex:           true,
+		FromIndex:             "registry-proxy.engineering.redhat.com/rh-osbs/iib-pub-pending:{{ OCP_VERSION }}",
+		PublishingCredentials: "staged-index-fbc-publishing-credentials",
+		RequestTimeoutSeconds: 1500,
+		BuildTimeoutSeconds:   1500,
+		AllowedPackages:       []string{"openshift-pipelines-operator-rh"},
+	}
+	intention, serviceAccountName := "staging", "release-index-image-staging"
+	if env == "prod" {
+		fbc.StagedIndex = false
+		fbc.FromIndex = "registry-proxy.engineering.redhat.com/rh-osbs/iib-pub:{{ OCP_VERSION }}"
+		fbc.TargetIndex = "quay.io/redhat-prod/redhat----redhat-operator-index:{{ OCP_VERSION }}"
+		fbc.PublishingCredentials = "fbc-production-publishing-credentials-redhat-prod"
+		intention, serviceAccountName = "production", "release-index-image-prod"
+	}
+	obj := newReleasePlanAdmission(a, a.ReleasePlanAdmissionName(env), ReleasePlanAdmissionSpec{
+		Origin: "tekton-ecosystem-tenant",
+		Policy: "fbc-tekton-ecosystem-" + env,
+		Data: ReleaseData{
+			ReleaseNotes: &ReleaseNotes{
+				ProductID:      []int{productID},
+				ProductName:    productName,
+				ProductVersion: "fbc",
+				References:     []string{documentationURL + "/"},
+				Type:           "RHEA",
+			},
+			FBC:       fbc,
+			Intention: intention,
+		},
+		Pipeline: ReleasePipeline{
+			ServiceAccountName: serviceAccountName,
+			Timeouts:           &Timeouts{Pipeline: "10h0m0s", Tasks: "10h0m0s"},
+			PipelineRef:        gitPipelineRef(releaseCatalogURL, "production", "pipelines/managed/fbc-release/fbc-release.yaml"),
+		},
+	})
+	obj.Metadata.Labels["pp.engineering.redhat.com/business-unit"] = "hybrid-platforms"
+	return obj
+}
+
+// cdnFiles are the tkn archives published to the developer portal.
+var cdnFiles = []CDNFile{
+	{Filename: "tkn-linux-amd64.tar.gz", Arch: "amd64", OS: "linux"},
+	{Filename: "tkn-linux-arm64.tar.gz", Arch: "arm64", OS: "linux"},
+	{Filename: "tkn-linux-ppc64le.tar.gz", Arch: "ppc64le", OS: "linux"},
+	{Filename: "tkn-linux-s390x.tar.gz", Arch: "s390x", OS: "linux"},
+	{Filename: "tkn-macos-amd64.tar.gz", Arch: "amd64", OS: "darwin"},
+	{Filename: "tkn-macos-arm64.tar.gz", Arch: "arm64", OS: "darwin"},
+	{Filename: "tkn-windows-amd64.tar.gz", Arch: "amd64", OS: "windows"},
+	{Filename: "tkn-windows-arm64.tar.gz", Arch: "arm64", OS: "windows"},
+}
+
+func newCDNReleasePlanAdmission(a Application, env string) *ReleasePlanAdmission {
+	mirrorOpenshiftPush, intention, cdnEnv := true, "production", "production"
+	if env != "prod" {
+		mirrorOpenshiftPush, intention, cdnEnv = false, "staging", "stage"
+	}
+	files := make([]CDNFile, len(cdnFiles))
+	for i, f := range cdnFiles {
+		f.Source = "/var/www/html/tkn/" + f.Filename
+		files[i] = f
+	}
+	obj := newReleasePlanAdmission(a, a.CDNReleasePlanAdmissionName(env), ReleasePlanAdmissionSpec{
+		Origin: a.Config.Namespace,
+		Policy: "registry-tekton-ecosystem-prod",
+		Data: ReleaseData{
+			ReleaseNotes: &ReleaseNotes{
+				ProductID:      []int{productID},
+				ProductName:    productName,
+				ProductVersion: a.Release.Version,
+			},
+			CDN: &CDNData{Env: cdnEnv},
+			Mapping: &Mapping{
+				Components: []MappingComponent{{
+					Name:  "serve-tkn-cli-" + hyphenize(a.Release.Version) + "-serve-tkn-cli",
+					Files: files,
+					ContentGateway: &ContentGateway{
+						ProductName:         "Cloud: OpenShift Pipelines",
+						ProductCode:         "pipelines",
+						ProductVersionName:  a.Release.BaseVersion(),
+						MirrorOpenshiftPush: &mirrorOpenshiftPush,
+						ContentType:         "binary",
+					},
+				}},
+				Defaults: MappingDefaults{PushSourceContainer: true},
+			},
+			Intention: intention,
+		},
+		Pipeline: ReleasePipeline{
+			ServiceAccountName: "release-developer-portal",
+			Timeouts:           &Timeouts{Pipeline: "5h0m0s", Tasks: "5h0m0s"},
+			PipelineRef:        gitPipelineRef(releaseCatalogURL, "production", "pipelines/managed/push-artifacts-to-cdn/push-artifacts-to-cdn.yaml"),
+		},
+	})
+	obj.Metadata.Annotations = map[string]string{"rhel_target": strings.TrimPrefix(a.Release.ImageSuffix, "-rh")}
+	return obj
+}
@@ -0,0 +1,120 @@
+package konflux
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxNameLength is the maximum length of a DNS-1123 label, which is also the
+// maximum length of a label value referencing the resource.
+const maxNameLength = 63
+
+// nameHashLength is the number of hex characters of the name hash appended to
+// truncated names.
+const nameHashLength = 8
+
+var dns1123LabelPattern = regexp.MustCompile("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$")
+
+// dnsName hyphenizes name and truncates it, see truncateName.
+func dnsName(name string) string {
+	return truncateName(hyphenize(name))
+}
+
+// truncateName truncates a name longer than maxNameLength and appends a short
+// hash of the full name so distinct names stay distinct. Suffixes must be part
+// of the name before truncating, for the result to be at most maxNameLength.
+func truncateName(name string) string {
+	if len(name) <= maxNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	prefix := strings.TrimRight(name[:maxNameLength-nameHashLength-1], "-.")
+	return prefix + "-" + hex.EncodeToString(sum[:])[:nameHashLength]
+}
+
+// validateName returns an error if name is not a valid DNS-1123 label. The
+// length is not checked, the names are truncated by dnsName.
+func validateName(kind, name string) error {
+	if !dns1123LabelPattern.MatchString(name) {
+		return fmt.Errorf("%s name %q is not a valid DNS-1123 label", kind, name)
+	}
+	return nil
+}
+
+// KonfluxName returns the name of the Konflux Application for the release,
+// e.g. "openshift-pipelines-core-1-23".
+func (a Application) KonfluxName() string {
+	return dnsName(a.Name + "-" + a.Release.Version)
+}
+
+// ResourceName returns the name of a resource of the application, e.g.
+// "openshift-pipelines-core-1-23-enterprise-contract" for the suffix
+// "enterprise-contract".
+func (a Application) ResourceName(suffix string) string {
+	return dnsName(a.Name + "-" + a.Release.Version + "-" + suffix)
+}
+
+// ReleasePlanName returns the name of the tenant ReleasePlan of the
+// application, e.g. "openshift-pipelines-core-1-23-rp".
+func (a Application) ReleasePlanName() string {
+	name := basename(a.Name) + "-" + a.Release.Version
+	if a.ReleaseToGitHub {
+		name += "-github"
+	}
+	return dnsName(name + "-rp")
+}
+
+// ReleasePlanAdmissionName returns the name of the ReleasePlanAdmission of the
+// application for the given environment.
+func (a Application) ReleasePlanAdmissionName(env string) string {
+	return dnsName(fmt.Sprintf("%s-%s-%s-%s", a.Config.Product, a.Release.Version, a.ShortName, env))
+}
+
+// CDNReleasePlanAdmissionName returns the name of the ReleasePlanAdmission
+// pushing the application artifacts to the CDN for the given environment.
+func (a Application) CDNReleasePlanAdmissionName(env string) string {
+	return dnsName(fmt.Sprintf("%s-%s-%s-cdn-%s", a.Config.Product, a.Release.Version, a.ShortName, env))
+}
+
+// CDNReleasePlanName returns the name of the ReleasePlan of the CDN
+// ReleasePlanAdmission of the application for the given environment.
+func (a Application) CDNReleasePlanName(env string) string {
+	return dnsName(fmt.Sprintf("%s-%s-%s-cdn-%s-rp", a.Config.Product, a.Release.Version, a.ShortName, env))
+}
+
+// KonfluxName returns the name of the Konflux Component, e.g.
+// "tektoncd-pipeline-1-23-controller".
+func (c Component) KonfluxName() string {
+	return dnsName(fmt.Sprintf("%s-%s-%s", basename(c.Repository.Name), c.Version.Version, c.Name))
+}
+
+// PipelineRunName returns the name of the build PipelineRun of the component
+// for an event, e.g. "pipeline-1-23-controller-on-push". The component name is
+// kept as configured, e.g. "operator-1-23-index-4.18-on-push", as the
+// PipelineRuns are named since the first releases.
+func (c Component) PipelineRunName(event string) string {
+	return truncateName(fmt.Sprintf("%s-%s-%s-%s", hyphenize(basename(c.Repository.Name)), hyphenize(c.Version.Version), c.Name, event))
+}
+
+// ServiceAccountName returns the name of the service account Konflux creates
+// to run the build pipelines of the component.
+func (c Component) ServiceAccountName() string {
+	return "build-pipeline-" + c.KonfluxName()
+}
+
+// validateNames checks that every resource name generated for the application
+// is a valid DNS-1123 label.
+func (a Application) validateNames() error {
+	if err := validateName("application", a.KonfluxName()); err != nil {
+		return err
+	}
+	for _, c := range a.Components {
+		if err := validateName("component", c.KonfluxName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
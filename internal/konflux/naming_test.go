@@ -0,0 +1,122 @@
+package konflux
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDNSName(t *testing.T) {
+	long := strings.Repeat("a", 60) + "-1.23-controller"
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "hyphenized",
+		in:   "openshift-pipelines-core-1.23",
+		want: "openshift-pipelines-core-1-23",
+	}, {
+		name: "max length kept",
+		in:   strings.Repeat("a", maxNameLength),
+		want: strings.Repeat("a", maxNameLength),
+	}, {
+		name: "truncated with a hash",
+		in:   long,
+		want: strings.Repeat("a", 54) + "-" + dnsName(long)[55:],
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dnsName(tt.in)
+			if got != tt.want {
+				t.Errorf("dnsName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if len(got) > maxNameLength {
+				t.Errorf("dnsName(%q) is %d characters long", tt.in, len(got))
+			}
+			if err := validateName("test", got); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	if a, b := dnsName(long+"-a"), dnsName(long+"-b"); a == b {
+		t.Errorf("distinct long names truncated to the same name %q", a)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"-pipeline", "pipeline-", "Pipeline", "pipeline.1"} {
+		if err := validateName("component", name); err == nil {
+			t.Errorf("validateName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestApplicationNames(t *testing.T) {
+	a := Application{
+		Name:      "openshift-pipelines-core",
+		ShortName: "core",
+		Release:   &Release{Version: "1.23"},
+		Config:    Config{Product: "openshift-pipelines"},
+	}
+	names := []struct {
+		got, want string
+	}{
+		{a.KonfluxName(), "openshift-pipelines-core-1-23"},
+		{a.ResourceName("enterprise-contract"), "openshift-pipelines-core-1-23-enterprise-contract"},
+		{a.ResourceName("release-tests-" + "arm64"), "openshift-pipelines-core-1-23-release-tests-arm64"},
+		{a.ReleasePlanName(), "openshift-pipelines-core-1-23-rp"},
+		{a.ReleasePlanAdmissionName("prod"), "openshift-pipelines-1-23-core-prod"},
+		{a.CDNReleasePlanAdmissionName("prod"), "openshift-pipelines-1-23-core-cdn-prod"},
+		{a.CDNReleasePlanName("prod"), "openshift-pipelines-1-23-core-cdn-prod-rp"},
+	}
+	for _, n := range names {
+		if n.got != n.want {
+			t.Errorf("got %q, want %q", n.got, n.want)
+		}
+	}
+
+	a.Name = "openshift-pipelines/core"
+	a.ReleaseToGitHub = true
+	if got, want := a.ReleasePlanName(), "core-1-23-github-rp"; got != want {
+		t.Errorf("ReleasePlanName() = %q, want %q", got, want)
+	}
+
+	a.Name = strings.Repeat("long-application-name-", 3)
+	for _, name := range []string{a.KonfluxName(), a.ResourceName("enterprise-contract"), a.ReleasePlanName()} {
+		if err := validateName("application", name); err != nil || len(name) > maxNameLength {
+			t.Errorf("invalid name %q (%d characters): %v", name, len(name), err)
+		}
+	}
+}
+
+func TestComponentNames(t *testing.T) {
+	c := Component{
+		Name:       "index-4.18",
+		Version:    Release{Version: "1.23"},
+		Repository: Repository{Name: "tektoncd/operator"},
+	}
+	names := []struct {
+		got, want string
+	}{
+		{c.KonfluxName(), "operator-1-23-index-4-18"},
+		{c.PipelineRunName("on-push"), "operator-1-23-index-4.18-on-push"},
+		{c.PipelineRunName("on-pull-request"), "operator-1-23-index-4.18-on-pull-request"},
+		{c.ServiceAccountName(), "build-pipeline-operator-1-23-index-4-18"},
+	}
+	for _, n := range names {
+		if n.got != n.want {
+			t.Errorf("got %q, want %q", n.got, n.want)
+		}
+	}
+
+	c.Name = "a-component-with-a-name-long-enough-to-be-truncated"
+	for _, event := range []string{"on-push", "on-pull-request"} {
+		name := c.PipelineRunName(event)
+		if len(name) > maxNameLength {
+			t.Errorf("PipelineRunName(%q) = %q is %d characters long", event, name, len(name))
+		}
+	}
+	if c.PipelineRunName("on-push") == c.PipelineRunName("on-pull-request") {
+		t.Error("the push and pull request PipelineRuns have the same name")
+	}
+}
@@ -0,0 +1,83 @@
+package konflux
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// DigestResolver resolves an image reference to the digest of its manifest.
+type DigestResolver interface {
+	Digest(ref string) (string, error)
+}
+
+// RegistryResolver resolves digests by querying the registries. The name and
+// remote options allow using another transport, e.g. a local registry in
+// tests.
+type RegistryResolver struct {
+	NameOptions   []name.Option
+	RemoteOptions []remote.Option
+}
+
+func (r RegistryResolver) Digest(ref string) (string, error) {
+	parsed, err := name.ParseReference(ref, r.NameOptions...)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(parsed, r.RemoteOptions...)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// isTaggedImage reports whether an ARG value is an image referenced by tag.
+func isTaggedImage(value string) bool {
+	if !strings.Contains(value, "/") || strings.Contains(value, "@") {
+		return false
+	}
+	_, err := name.NewTag(value, name.StrictValidation)
+	return err == nil
+}
+
+// ImageArgs returns the images referenced by tag in the Dockerfile ARGs of
+// the component, before pinning.
+func ImageArgs(component Component) ([]string, error) {
+	component.DockerFileOptions.Pins = nil
+	args, err := getArgs(component)
+	if err != nil {
+		return nil, err
+	}
+	var images []string
+	for _, value := range args {
+		if isTaggedImage(value) {
+			images = append(images, value)
+		}
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// PinImages resolves the images to "repository@sha256:..." references, keyed
+// by the tagged reference.
+func PinImages(images []string, resolver DigestResolver) (map[string]string, error) {
+	pins := map[string]string{}
+	for _, image := range images {
+		if _, ok := pins[image]; ok {
+			continue
+		}
+		tag, err := name.NewTag(image, name.StrictValidation)
+		if err != nil {
+			return nil, err
+		}
+		digest, err := resolver.Digest(image)
+		if err != nil {
+			return nil, err
+		}
+		pins[image] = tag.Context().Name() + "@" + digest
+	}
+	return pins, nil
+}
@@ -0,0 +1,126 @@
+package konflux
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// testRegistry starts a local registry serving a random image tagged
+// <registry>/ubi9/go-toolset:1.23 and returns the registry host and the
+// digest of the image.
+func testRegistry(t *testing.T) (string, string) {
+	t.Helper()
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(host+"/ubi9/go-toolset:1.23", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, image); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := image.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host, digest.String()
+}
+
+func TestRegistryResolver(t *testing.T) {
+	host, digest := testRegistry(t)
+	resolver := RegistryResolver{NameOptions: []name.Option{name.Insecure}}
+	tests := []struct {
+		name    string
+		ref     string
+		wantErr bool
+	}{{
+		name: "tagged",
+		ref:  host + "/ubi9/go-toolset:1.23",
+	}, {
+		name: "digest pinned",
+		ref:  host + "/ubi9/go-toolset@" + digest,
+	}, {
+		name:    "unknown tag",
+		ref:     host + "/ubi9/go-toolset:1.24",
+		wantErr: true,
+	}, {
+		name:    "unknown repository",
+		ref:     host + "/ubi9/unknown:1.23",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Digest(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Digest(%q) = %q, want an error", tt.ref, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != digest {
+				t.Errorf("Digest(%q) = %q, want %q", tt.ref, got, digest)
+			}
+		})
+	}
+}
+
+func TestPinImages(t *testing.T) {
+	host, digest := testRegistry(t)
+	resolver := RegistryResolver{NameOptions: []name.Option{name.Insecure}}
+	image := host + "/ubi9/go-toolset:1.23"
+
+	pins, err := PinImages([]string{image, image}, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{image: host + "/ubi9/go-toolset@" + digest}
+	if !reflect.DeepEqual(pins, want) {
+		t.Errorf("PinImages() = %v, want %v", pins, want)
+	}
+
+	if _, err := PinImages([]string{image, host + "/ubi9/go-toolset:1.24"}, resolver); err == nil {
+		t.Error("PinImages() with an unresolvable image = nil, want an error")
+	}
+}
+
+func TestImageArgs(t *testing.T) {
+	component := Component{
+		Version: Release{Version: "1.23"},
+		DockerFileOptions: DockerFileOptions{
+			Args: map[string]string{
+				"GO_BUILDER": "registry.access.redhat.com/ubi9/go-toolset:1.23",
+				"RUNTIME":    "registry.access.redhat.com/ubi9/ubi-minimal@sha256:" + strings.Repeat("a", 64),
+				"NAME":       "controller",
+			},
+			Pins: map[string]string{"registry.access.redhat.com/ubi9/go-toolset:1.23": "registry.access.redhat.com/ubi9/go-toolset@sha256:" + strings.Repeat("b", 64)},
+		},
+	}
+	images, err := ImageArgs(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The default GO_BUILDER is overridden; pinned and digest images are
+	// listed by tag only.
+	want := []string{"registry.access.redhat.com/ubi9/go-toolset:1.23"}
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("ImageArgs() = %v, want %v", images, want)
+	}
+}
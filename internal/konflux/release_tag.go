@@ -0,0 +1,124 @@
+package konflux
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var releaseTagRe = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-RC-(\d+))?$`)
+
+// ReleaseTag is the tag of a numbered release, e.g. "1.22.0-RC-1" for a
+// release candidate or "1.22.3".
+type ReleaseTag struct {
+	Major int
+	Minor int
+	Patch int
+	// RC is the release candidate number, 0 for a final release.
+	RC int
+}
+
+// ParseReleaseTag parses a release tag, with or without the "v" prefix.
+func ParseReleaseTag(tag string) (ReleaseTag, error) {
+	m := releaseTagRe.FindStringSubmatch(tag)
+	if m == nil {
+		return ReleaseTag{}, fmt.Errorf("invalid release tag %q, expected x.y.z or x.y.z-RC-n", tag)
+	}
+	var t ReleaseTag
+	for i, n := range []*int{&t.Major, &t.Minor, &t.Patch, &t.RC} {
+		if m[i+1] == "" {
+			continue
+		}
+		v, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return ReleaseTag{}, fmt.Errorf("invalid release tag %q: %w", tag, err)
+		}
+		*n = v
+	}
+	if m[4] != "" && t.RC == 0 {
+		return ReleaseTag{}, fmt.Errorf("invalid release tag %q, release candidates start at RC-1", tag)
+	}
+	return t, nil
+}
+
+// FirstReleaseCandidate returns the first tag of a release version, e.g.
+// "1.23.0-RC-1" for "1.23".
+func FirstReleaseCandidate(version string) (ReleaseTag, error) {
+	t, err := ParseReleaseTag(version + ".0-RC-1")
+	if err != nil {
+		return ReleaseTag{}, fmt.Errorf("invalid release version %q, expected x.y", version)
+	}
+	return t, nil
+}
+
+func (t ReleaseTag) String() string {
+	if t.IsRC() {
+		return fmt.Sprintf("%s-RC-%d", t.BaseVersion(), t.RC)
+	}
+	return t.BaseVersion()
+}
+
+// BaseVersion returns the version without the release candidate suffix.
+func (t ReleaseTag) BaseVersion() string {
+	return fmt.Sprintf("%d.%d.%d", t.Major, t.Minor, t.Patch)
+}
+
+// MinorVersion returns the release version the tag belongs to, e.g. "1.22".
+func (t ReleaseTag) MinorVersion() string {
+	return fmt.Sprintf("%d.%d", t.Major, t.Minor)
+}
+
+func (t ReleaseTag) IsRC() bool {
+	return t.RC > 0
+}
+
+// GA reports whether the tag is the first final release of its version.
+func (t ReleaseTag) GA() bool {
+	return !t.IsRC() && t.Patch == 0
+}
+
+// NextRC returns the next release candidate. The first release candidate of
+// the next patch follows a final release.
+func (t ReleaseTag) NextRC() ReleaseTag {
+	if !t.IsRC() {
+		t.Patch++
+	}
+	t.RC++
+	return t
+}
+
+// Finalize returns the final release of a release candidate.
+func (t ReleaseTag) Finalize() ReleaseTag {
+	t.RC = 0
+	return t
+}
+
+// NextPatch returns the next final patch release, a release candidate is
+// followed by the next patch of its final release.
+func (t ReleaseTag) NextPatch() ReleaseTag {
+	t.Patch++
+	t.RC = 0
+	return t
+}
+
+// Compare returns -1, 0 or +1 whether t is lower, equal or greater than o. A
+// release candidate is lower than its final release.
+func (t ReleaseTag) Compare(o ReleaseTag) int {
+	if c := cmp.Compare(t.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(t.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(t.Patch, o.Patch); c != 0 {
+		return c
+	}
+	if t.IsRC() != o.IsRC() {
+		if t.IsRC() {
+			return -1
+		}
+		return 1
+	}
+	return cmp.Compare(t.RC, o.RC)
+}
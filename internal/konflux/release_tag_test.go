@@ -0,0 +1,193 @@
+package konflux
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseReleaseTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    ReleaseTag
+		wantErr bool
+	}{
+		{tag: "1.23.0", want: ReleaseTag{Major: 1, Minor: 23}},
+		{tag: "v1.23.4", want: ReleaseTag{Major: 1, Minor: 23, Patch: 4}},
+		{tag: "1.23.0-RC-1", want: ReleaseTag{Major: 1, Minor: 23, RC: 1}},
+		{tag: "1.23.0-RC-10", want: ReleaseTag{Major: 1, Minor: 23, RC: 10}},
+		{tag: "1.23", wantErr: true},
+		{tag: "1.23.0-RC-0", wantErr: true},
+		{tag: "1.23.0-rc-1", wantErr: true},
+		{tag: "1.23.0-RC1", wantErr: true},
+		{tag: "1.23.0-RC-", wantErr: true},
+		{tag: "1.23.x", wantErr: true},
+		{tag: " 1.23.0", wantErr: true},
+		{tag: "next", wantErr: true},
+		{tag: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReleaseTag(tt.tag)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseReleaseTag(%q) = %v, want an error", tt.tag, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseReleaseTag(%q): %v", tt.tag, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReleaseTag(%q) = %+v, want %+v", tt.tag, got, tt.want)
+		}
+		if s := got.String(); s != strings.TrimPrefix(tt.tag, "v") {
+			t.Errorf("ParseReleaseTag(%q).String() = %q", tt.tag, s)
+		}
+	}
+}
+
+func mustParseReleaseTag(t *testing.T, tag string) ReleaseTag {
+	t.Helper()
+	parsed, err := ParseReleaseTag(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestReleaseTagCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.23.0-RC-10", "1.23.0-RC-9", 1},
+		{"1.23.0-RC-9", "1.23.0-RC-10", -1},
+		{"1.23.0-RC-2", "1.23.0-RC-2", 0},
+		{"1.23.0-RC-10", "1.23.0", -1},
+		{"1.23.0", "1.23.0-RC-1", 1},
+		{"1.23.1-RC-1", "1.23.0", 1},
+		{"1.23.10", "1.23.9", 1},
+		{"1.9.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+	}
+	for _, tt := range tests {
+		if got := mustParseReleaseTag(t, tt.a).Compare(mustParseReleaseTag(t, tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	tags := []string{"1.23.0", "1.23.0-RC-10", "1.23.1", "1.23.0-RC-9", "1.23.0-RC-1"}
+	parsed := make([]ReleaseTag, 0, len(tags))
+	for _, tag := range tags {
+		parsed = append(parsed, mustParseReleaseTag(t, tag))
+	}
+	slices.SortFunc(parsed, ReleaseTag.Compare)
+	var sorted []string
+	for _, tag := range parsed {
+		sorted = append(sorted, tag.String())
+	}
+	want := []string{"1.23.0-RC-1", "1.23.0-RC-9", "1.23.0-RC-10", "1.23.0", "1.23.1"}
+	if !slices.Equal(sorted, want) {
+		t.Errorf("sorted tags = %v, want %v", sorted, want)
+	}
+}
+
+func TestReleaseTagLifecycle(t *testing.T) {
+	tests := []struct {
+		tag                         string
+		nextRC, finalize, nextPatch string
+		ga                          bool
+	}{{
+		tag:       "1.23.0-RC-1",
+		nextRC:    "1.23.0-RC-2",
+		finalize:  "1.23.0",
+		nextPatch: "1.23.1",
+	}, {
+		tag:       "1.23.0-RC-9",
+		nextRC:    "1.23.0-RC-10",
+		finalize:  "1.23.0",
+		nextPatch: "1.23.1",
+	}, {
+		tag:       "1.23.0",
+		nextRC:    "1.23.1-RC-1",
+		finalize:  "1.23.0",
+		nextPatch: "1.23.1",
+		ga:        true,
+	}, {
+		tag:       "1.23.9",
+		nextRC:    "1.23.10-RC-1",
+		finalize:  "1.23.9",
+		nextPatch: "1.23.10",
+	}, {
+		tag:       "1.23.10",
+		nextRC:    "1.23.11-RC-1",
+		finalize:  "1.23.10",
+		nextPatch: "1.23.11",
+	}}
+	for _, tt := range tests {
+		tag := mustParseReleaseTag(t, tt.tag)
+		if got := tag.NextRC().String(); got != tt.nextRC {
+			t.Errorf("%s.NextRC() = %s, want %s", tt.tag, got, tt.nextRC)
+		}
+		if got := tag.Finalize().String(); got != tt.finalize {
+			t.Errorf("%s.Finalize() = %s, want %s", tt.tag, got, tt.finalize)
+		}
+		if got := tag.NextPatch().String(); got != tt.nextPatch {
+			t.Errorf("%s.NextPatch() = %s, want %s", tt.tag, got, tt.nextPatch)
+		}
+		if got := tag.GA(); got != tt.ga {
+			t.Errorf("%s.GA() = %t, want %t", tt.tag, got, tt.ga)
+		}
+		if got := tag.MinorVersion(); got != "1.23" {
+			t.Errorf("%s.MinorVersion() = %s, want 1.23", tt.tag, got)
+		}
+	}
+}
+
+func TestFirstReleaseCandidate(t *testing.T) {
+	tag, err := FirstReleaseCandidate("1.23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.String() != "1.23.0-RC-1" {
+		t.Errorf("FirstReleaseCandidate(1.23) = %s, want 1.23.0-RC-1", tag)
+	}
+	for _, version := range []string{"1.23.0", "next", ""} {
+		if _, err := FirstReleaseCandidate(version); err == nil {
+			t.Errorf("FirstReleaseCandidate(%q) = nil error, want an error", version)
+		}
+	}
+}
+
+func TestReleaseVersions(t *testing.T) {
+	tests := []struct {
+		release    Release
+		full, base string
+		ga         bool
+		wantErr    bool
+	}{
+		{release: Release{Version: "1.23", ReleaseTag: "1.23.0-RC-10"}, full: "v1.23.0-RC-10", base: "1.23.0"},
+		{release: Release{Version: "1.23", ReleaseTag: "1.23.0"}, full: "v1.23.0", base: "1.23.0", ga: true},
+		// The sprig hasSuffix "0" of the CDN product reported 1.23.10 as GA.
+		{release: Release{Version: "1.23", ReleaseTag: "1.23.10"}, full: "v1.23.10", base: "1.23.10"},
+		{release: Release{Version: "next", ReleaseTag: "next"}, full: "next", base: "next"},
+		{release: Release{Version: "1.23", ReleaseTag: "1.22.0"}, full: "v1.22.0", base: "1.22.0", ga: true, wantErr: true},
+		{release: Release{Version: "1.23", ReleaseTag: "1.23.0-rc1"}, full: "v1.23.0-rc1", base: "1.23.0-rc1", wantErr: true},
+	}
+	for _, tt := range tests {
+		r := tt.release
+		if got := r.FullVersion(); got != tt.full {
+			t.Errorf("%s FullVersion() = %s, want %s", r.ReleaseTag, got, tt.full)
+		}
+		if got := r.BaseVersion(); got != tt.base {
+			t.Errorf("%s BaseVersion() = %s, want %s", r.ReleaseTag, got, tt.base)
+		}
+		if got := r.GA(); got != tt.ga {
+			t.Errorf("%s GA() = %t, want %t", r.ReleaseTag, got, tt.ga)
+		}
+		if err := r.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s Validate() = %v, want error %t", r.ReleaseTag, err, tt.wantErr)
+		}
+	}
+}
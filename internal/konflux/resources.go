@@ -0,0 +1,299 @@
+package konflux
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	appstudioV1alpha1 = "appstudio.redhat.com/v1alpha1"
+	appstudioV1beta2  = "appstudio.redhat.com/v1beta2"
+)
+
+// Object is a Kubernetes object generated for Konflux. Generated objects can
+// be post-processed through their metadata before being written.
+//
+// The Application, Component, ImageRepository, IntegrationTestScenario,
+// ReleasePlan and ReleasePlanAdmission files are written from objects only;
+// the PipelineRuns, workflows and other files are rendered from templates.
+type Object interface {
+	GetMetadata() *ObjectMeta
+}
+
+// ObjectHeader holds the type and object metadata shared by every generated
+// object.
+type ObjectHeader struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   ObjectMeta `yaml:"metadata"`
+}
+
+func (h *ObjectHeader) GetMetadata() *ObjectMeta {
+	return &h.Metadata
+}
+
+type ObjectMeta struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+func newObjectHeader(apiVersion, kind, name string) ObjectHeader {
+	return ObjectHeader{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Metadata:   ObjectMeta{Name: name},
+	}
+}
+
+type Param struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type PipelineRef struct {
+	Resolver string  `yaml:"resolver"`
+	Params   []Param `yaml:"params"`
+}
+
+// gitPipelineRef returns a reference to a pipeline resolved from a git
+// repository.
+func gitPipelineRef(url, revision, pathInRepo string) PipelineRef {
+	return PipelineRef{
+		Resolver: "git",
+		Params: []Param{
+			{Name: "url", Value: url},
+			{Name: "revision", Value: revision},
+			{Name: "pathInRepo", Value: pathInRepo},
+		},
+	}
+}
+
+type KonfluxApplication struct {
+	ObjectHeader `yaml:",inline"`
+	Spec         ApplicationSpec `yaml:"spec"`
+}
+
+type ApplicationSpec struct {
+	DisplayName string `yaml:"displayName"`
+}
+
+type KonfluxComponent struct {
+	ObjectHeader `yaml:",inline"`
+	Spec         ComponentSpec `yaml:"spec"`
+}
+
+type ComponentSpec struct {
+	ComponentName  string          `yaml:"componentName"`
+	Application    string          `yaml:"application"`
+	BuildNudgesRef []string        `yaml:"build-nudges-ref"`
+	Source         ComponentSource `yaml:"source"`
+}
+
+type ComponentSource struct {
+	Git GitSource `yaml:"git"`
+}
+
+type GitSource struct {
+	URL           string `yaml:"url"`
+	DockerfileURL string `yaml:"dockerfileUrl"`
+	Revision      string `yaml:"revision"`
+}
+
+type ImageRepository struct {
+	ObjectHeader `yaml:",inline"`
+	Spec         ImageRepositorySpec `yaml:"spec"`
+}
+
+type ImageRepositorySpec struct {
+	Image         ImageSpec      `yaml:"image"`
+	Notifications []Notification `yaml:"notifications,omitempty"`
+}
+
+type ImageSpec struct {
+	Name       string `yaml:"name"`
+	Visibility string `yaml:"visibility"`
+}
+
+type Notification struct {
+	Config NotificationConfig `yaml:"config"`
+	Event  string             `yaml:"event"`
+	Method string             `yaml:"method"`
+	Title  string             `yaml:"title"`
+}
+
+type NotificationConfig struct {
+	URL string `yaml:"url"`
+}
+
+type IntegrationTestScenario struct {
+	ObjectHeader `yaml:",inline"`
+	Spec         IntegrationTestScenarioSpec `yaml:"spec"`
+}
+
+type IntegrationTestScenarioSpec struct {
+	Application string        `yaml:"application"`
+	Contexts    []TestContext `yaml:"contexts"`
+	Params      []Param       `yaml:"params"`
+	ResolverRef PipelineRef   `yaml:"resolverRef"`
+}
+
+type TestContext struct {
+	Description string `yaml:"description"`
+	Name        string `yaml:"name"`
+}
+
+type ReleasePlan struct {
+	ObjectHeader `yaml:",inline"`
+	Spec         ReleasePlanSpec `yaml:"spec"`
+}
+
+type ReleasePlanSpec struct {
+	Application    string           `yaml:"application"`
+	Target         string           `yaml:"target,omitempty"`
+	TenantPipeline *ReleasePipeline `yaml:"tenantPipeline,omitempty"`
+	Data           *ReleaseData     `yaml:"data,omitempty"`
+	Collectors     *Collectors      `yaml:"collectors,omitempty"`
+}
+
+type ReleasePlanAdmission struct {
+	ObjectHeader `yaml:",inline"`
+	Spec         ReleasePlanAdmissionSpec `yaml:"spec"`
+}
+
+type ReleasePlanAdmissionSpec struct {
+	Applications []string        `yaml:"applications"`
+	Origin       string          `yaml:"origin"`
+	Policy       string          `yaml:"policy"`
+	Data         ReleaseData     `yaml:"data"`
+	Pipeline     ReleasePipeline `yaml:"pipeline"`
+}
+
+type ReleasePipeline struct {
+	ServiceAccountName string      `yaml:"serviceAccountName,omitempty"`
+	Timeouts           *Timeouts   `yaml:"timeouts,omitempty"`
+	PipelineRef        PipelineRef `yaml:"pipelineRef"`
+	Params             []Param     `yaml:"params,omitempty"`
+}
+
+type Timeouts struct {
+	Pipeline string `yaml:"pipeline"`
+	Tasks    string `yaml:"tasks"`
+}
+
+type ReleaseData struct {
+	ReleaseNotes                        *ReleaseNotes `yaml:"releaseNotes,omitempty"`
+	CDN                                 *CDNData      `yaml:"cdn,omitempty"`
+	FBC                                 *FBCData      `yaml:"fbc,omitempty"`
+	Mapping                             *Mapping      `yaml:"mapping,omitempty"`
+	Intention                           string        `yaml:"intention,omitempty"`
+	EnforceContainerFirstSecurityLabels bool          `yaml:"enforceContainerFirstSecurityLabels,omitempty"`
+}
+
+type ReleaseNotes struct {
+	ProductID      []int    `yaml:"product_id,omitempty"`
+	ProductName    string   `yaml:"product_name,omitempty"`
+	ProductVersion string   `yaml:"product_version,omitempty"`
+	References     []string `yaml:"references,omitempty"`
+	Type           string   `yaml:"type,omitempty"`
+	Solution       string   `yaml:"solution,omitempty"`
+	Description    string   `yaml:"description,omitempty"`
+	Topic          string   `yaml:"topic,omitempty"`
+	Synopsis       string   `yaml:"synopsis,omitempty"`
+}
+
+type CDNData struct {
+	Env string `yaml:"env"`
+}
+
+type FBCData struct {
+	StagedIndex           bool     `yaml:"stagedIndex,omitempty"`
+	FromIndex             string   `yaml:"fromIndex"`
+	TargetIndex           string   `yaml:"targetIndex"`
+	PublishingCredentials string   `yaml:"publishingCredentials"`
+	RequestTimeoutSeconds int      `yaml:"requestTimeoutSeconds"`
+	BuildTimeoutSeconds   int      `yaml:"buildTimeoutSeconds"`
+	AllowedPackages       []string `yaml:"allowedPackages"`
+}
+
+type Mapping struct {
+	Components []MappingComponent `yaml:"components,omitempty"`
+	Defaults   MappingDefaults    `yaml:"defaults"`
+}
+
+type MappingComponent struct {
+	Name                string              `yaml:"name"`
+	Repositories        []MappingRepository `yaml:"repositories,omitempty"`
+	Files               []CDNFile           `yaml:"files,omitempty"`
+	ContentGateway      *ContentGateway     `yaml:"contentGateway,omitempty"`
+	PushSourceContainer bool                `yaml:"pushSourceContainer,omitempty"`
+}
+
+type MappingRepository struct {
+	URL string `yaml:"url"`
+}
+
+type MappingDefaults struct {
+	Tags                []string        `yaml:"tags,omitempty"`
+	ContentGateway      *ContentGateway `yaml:"contentGateway,omitempty"`
+	PushSourceContainer bool            `yaml:"pushSourceContainer,omitempty"`
+}
+
+type CDNFile struct {
+	Filename string `yaml:"filename"`
+	Source   string `yaml:"source"`
+	Arch     string `yaml:"arch"`
+	OS       string `yaml:"os"`
+}
+
+type ContentGateway struct {
+	ProductName         string `yaml:"productName,omitempty"`
+	ProductCode         string `yaml:"productCode,omitempty"`
+	ProductVersionName  string `yaml:"productVersionName"`
+	MirrorOpenshiftPush *bool  `yaml:"mirrorOpenshiftPush,omitempty"`
+	ContentType         string `yaml:"contentType,omitempty"`
+}
+
+type Collectors struct {
+	ServiceAccountName string          `yaml:"serviceAccountName"`
+	Secrets            []string        `yaml:"secrets"`
+	Items              []CollectorItem `yaml:"items"`
+}
+
+type CollectorItem struct {
+	Name    string  `yaml:"name"`
+	Params  []Param `yaml:"params"`
+	Timeout int     `yaml:"timeout"`
+	Type    string  `yaml:"type"`
+}
+
+// writeObjects serialises the objects as a multi-document YAML file prefixed
+// with the autogenerated header of the application.
+func writeObjects(filePath string, application Application, objs ...Object) error {
+	header, err := Eval(autoGeneratedHeader, application)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(header + "\n")
+	for _, obj := range objs {
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("serialising %s: %w", obj.GetMetadata().Name, err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(out)
+	}
+	if err := validateYAML(buf.Bytes(), application.Config.ValidateKinds); err != nil {
+		return fmt.Errorf("objects generated for %s: %w", filePath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, buf.Bytes(), 0o644)
+}
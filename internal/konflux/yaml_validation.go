@@ -0,0 +1,137 @@
+package konflux
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var yamlErrorLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// knownFields lists, for the kinds generated by this package, the fields
+// accepted at the top level, in metadata and in spec.
+var knownFields = map[string]map[string][]string{
+	"*": {
+		"":         {"apiVersion", "kind", "metadata", "spec", "status"},
+		"metadata": {"name", "generateName", "namespace", "labels", "annotations", "creationTimestamp"},
+	},
+	"Application": {
+		"spec": {"displayName", "description", "appModelRepository", "gitopsRepository"},
+	},
+	"Component": {
+		"spec": {"componentName", "application", "build-nudges-ref", "source", "containerImage", "dockerfileUrl", "env", "replicas", "resources", "route", "skipGitOpsResourceGeneration", "targetPort"},
+	},
+	"ImageRepository": {
+		"spec": {"image", "notifications"},
+	},
+	"ReleasePlan": {
+		"spec": {"application", "collectors", "data", "finalPipeline", "pipeline", "releaseGracePeriodDays", "target", "tenantPipeline"},
+	},
+	"ReleasePlanAdmission": {
+		"spec": {"applications", "collectors", "data", "environment", "origin", "pipeline", "policy"},
+	},
+	"IntegrationTestScenario": {
+		"spec": {"application", "contexts", "dependents", "environment", "params", "resolverRef"},
+	},
+	"PipelineRun": {
+		"spec": {"params", "pipelineRef", "pipelineSpec", "taskRunSpecs", "taskRunTemplate", "timeouts", "workspaces"},
+	},
+}
+
+// validateYAML parses every document of a rendered file and, if validateKinds
+// is set, checks the fields of the known Kubernetes kinds.
+func validateYAML(data []byte, validateKinds bool) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for doc := 1; ; doc++ {
+		var obj interface{}
+		err := decoder.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return yamlError(data, err)
+		}
+		if !validateKinds {
+			continue
+		}
+		if err := validateKind(obj); err != nil {
+			return fmt.Errorf("document %d: %w", doc, err)
+		}
+	}
+}
+
+// yamlError adds the offending line of the rendered file to a parse error.
+func yamlError(data []byte, err error) error {
+	m := yamlErrorLinePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	line, _ := strconv.Atoi(m[1])
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	return fmt.Errorf("invalid YAML at line %d %q: %w", line, lines[line-1], err)
+}
+
+func validateKind(obj interface{}) error {
+	m, ok := obj.(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+	kind, _ := m["kind"].(string)
+	fields, ok := knownFields[kind]
+	if !ok {
+		return nil
+	}
+	for _, section := range []string{"", "metadata", "spec"} {
+		allowed := slices.Concat(knownFields["*"][section], fields[section])
+		if len(allowed) == 0 {
+			continue
+		}
+		values := m
+		if section != "" {
+			values, _ = m[section].(map[interface{}]interface{})
+		}
+		var unknown []string
+		for k := range values {
+			key := fmt.Sprint(k)
+			if !slices.Contains(allowed, key) {
+				unknown = append(unknown, key)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			path := kind
+			if section != "" {
+				path += "." + section
+			}
+			return fmt.Errorf("unknown fields in %s: %s", path, strings.Join(unknown, ", "))
+		}
+	}
+	return nil
+}
+
+// dataIdentity returns a short description of the data a template was rendered
+// with, to be used in error messages.
+func dataIdentity(data interface{}) string {
+	switch d := data.(type) {
+	case Component:
+		return "component " + d.KonfluxName()
+	case Repository:
+		return "repository " + d.Name
+	case Application:
+		return "application " + d.KonfluxName()
+	case interface{ KonfluxName() string }:
+		return d.KonfluxName()
+	}
+	return fmt.Sprintf("%T", data)
+}
@@ -0,0 +1,77 @@
+package konflux
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateYAML(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		validateKinds bool
+		wantErr       string
+	}{{
+		name: "valid documents",
+		data: "---\napiVersion: v1\nkind: Application\nmetadata:\n  name: a\nspec:\n  displayName: a\n---\nkind: Component\nspec:\n  application: a\n",
+	}, {
+		name:    "invalid YAML with its line",
+		data:    "kind: Application\nmetadata:\n  name: a\n labels: {}\n",
+		wantErr: `invalid YAML at line 3 "  name: a": yaml: line 3: did not find expected key`,
+	}, {
+		name:    "invalid YAML in a later document",
+		data:    "kind: Application\n---\nkind: Component\nspec:\n  - a\n  b: c\n",
+		wantErr: `invalid YAML at line 5 "  - a"`,
+	}, {
+		name: "unknown fields not checked",
+		data: "kind: Component\nspec:\n  unknown: a\n",
+	}, {
+		name:          "unknown spec field",
+		data:          "kind: Component\nspec:\n  application: a\n  buildNudgesRef: [b]\n",
+		validateKinds: true,
+		wantErr:       "document 1: unknown fields in Component.spec: buildNudgesRef",
+	}, {
+		name:          "unknown metadata field in the second document",
+		data:          "kind: Application\n---\nkind: ReleasePlan\nmetadata:\n  name: a\n  label: {}\n  z: 1\n",
+		validateKinds: true,
+		wantErr:       "document 2: unknown fields in ReleasePlan.metadata: label, z",
+	}, {
+		name:          "unknown top-level field",
+		data:          "kind: ImageRepository\nspecs: {}\n",
+		validateKinds: true,
+		wantErr:       "unknown fields in ImageRepository: specs",
+	}, {
+		name:          "other kinds not checked",
+		data:          "kind: ConfigMap\ndata:\n  a: b\n",
+		validateKinds: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateYAML([]byte(tt.data), tt.validateKinds)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateYAML() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateYAML() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateFileFromTemplateDirectoryError(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	application := Application{Name: "core", Release: &Release{Version: "1.23"}}
+	err := generateFileFromTemplate("product-cdn.yaml", application, filepath.Join(file, "1.23.0.yaml"), application)
+	if err == nil || !strings.Contains(err.Error(), "creating directory") {
+		t.Fatalf("generateFileFromTemplate() = %v, want a directory creation error", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Package konfluxtest provides helpers for the tests of the Konflux
+// configuration tools.
+package konfluxtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// WriteConfigDir writes the files of a config directory, keyed by their path
+// relative to it, and returns the directory.
+func WriteConfigDir(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
@@ -8,11 +8,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	gyaml "github.com/ghodss/yaml"
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"golang.org/x/mod/semver"
 	"gopkg.in/yaml.v2"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 )
@@ -29,8 +31,30 @@ type Repository struct {
 	GolangVersion      string             `json:"golang" yaml:"golang"`
 }
 
+// E2E selects the tests generated for the repository. The workflows are:
+//   - tasks, the e2e tests of the tasks against each OpenShift Pipelines
+//     version (make test-e2e-openshift),
+//   - unit, the unit tests, without a cluster (make test-unit),
+//   - operator, the e2e tests after installing each OpenShift Pipelines
+//     version with the operator (make install-openshift-pipelines test-e2e),
+//   - upgrade, the e2e tests after upgrading between each two consecutive
+//     OpenShift Pipelines versions, in version order whatever their order in
+//     the config (make upgrade-openshift-pipelines test-e2e).
+//
+// The OpenShift Pipelines version under test is passed to every make target
+// as OSP_VERSION.
 type E2E struct {
 	Workflow string `json:"workflow" yaml:"workflow"`
+	// Workflows are additional workflows, e.g. unit along with operator.
+	Workflows []string `json:"workflows" yaml:"workflows"`
+}
+
+// workflows returns the selected workflows, Workflow first.
+func (e E2E) workflows() []string {
+	if e.Workflow == "" {
+		return e.Workflows
+	}
+	return append([]string{e.Workflow}, e.Workflows...)
 }
 
 type OpenShift struct {
@@ -235,43 +259,110 @@ func GenerateReleaseBuildConfigurationFromConfig(repo *Repository) ([]ReleaseBui
 
 func generateTestFromConfig(repo *Repository) ([]cioperatorapi.TestStepConfiguration, error) {
 	tests := []cioperatorapi.TestStepConfiguration{}
-	clusterClaim := getClusterClaim(repo.OpenShift.Version)
-	switch repo.E2E.Workflow {
-	case "tasks":
-		for _, version := range repo.OpenShiftPipelines.Versions {
-			version := version
+	workflows := repo.E2E.workflows()
+	if len(workflows) == 0 {
+		return tests, fmt.Errorf("no workflow configured")
+	}
+	ocpVersion := k8sNameString(repo.OpenShift.Version)
+	for _, workflow := range workflows {
+		switch workflow {
+		case "tasks":
+			for _, version := range repo.OpenShiftPipelines.Versions {
+				tests = append(tests, clusterTest(repo,
+					fmt.Sprintf("osp-%s-ocp-%s-e2e", k8sNameString(version), ocpVersion),
+					literalStep("e2e", fmt.Sprintf("make OSP_VERSION=%s test-e2e-openshift", version)),
+				))
+			}
+		case "unit":
 			tests = append(tests, cioperatorapi.TestStepConfiguration{
-				As:           fmt.Sprintf("osp-%s-ocp-%s-e2e", k8sNameString(version), k8sNameString(repo.OpenShift.Version)),
-				Cluster:      "build05",
-				ClusterClaim: clusterClaim,
-				MultiStageTestConfiguration: &cioperatorapi.MultiStageTestConfiguration{
-					AllowSkipOnSuccess:       pTrue(),
-					AllowBestEffortPostSteps: pTrue(),
-					Post:                     getTaskPostSteps(),
-					Test: []cioperatorapi.TestStep{{
-						LiteralTestStep: &cioperatorapi.LiteralTestStep{
-							As:       "e2e",
-							Cli:      "latest",
-							Commands: fmt.Sprintf("make OSP_VERSION=%s test-e2e-openshift", version),
-							From:     "base-tests",
-							Resources: cioperatorapi.ResourceRequirements{
-								Requests: cioperatorapi.ResourceList{
-									"cpu": "100m",
-								},
-							},
-						},
-					}},
-					Workflow: stringPtr("generic-claim"),
+				As:       "unit",
+				Commands: "make test-unit",
+				ContainerTestConfiguration: &cioperatorapi.ContainerTestConfiguration{
+					From: "src",
 				},
 				SkipIfOnlyChanged: skipIfOnlyChanged,
 			})
+		case "operator":
+			for _, version := range repo.OpenShiftPipelines.Versions {
+				tests = append(tests, clusterTest(repo,
+					fmt.Sprintf("osp-%s-ocp-%s-operator-e2e", k8sNameString(version), ocpVersion),
+					literalStep("install", fmt.Sprintf("make OSP_VERSION=%s install-openshift-pipelines", version)),
+					literalStep("e2e", fmt.Sprintf("make OSP_VERSION=%s test-e2e", version)),
+				))
+			}
+		case "upgrade":
+			versions, err := sortedVersions(repo.OpenShiftPipelines.Versions)
+			if err != nil {
+				return tests, fmt.Errorf("workflow %q: %w", workflow, err)
+			}
+			if len(versions) < 2 {
+				return tests, fmt.Errorf("workflow %q needs at least two openshift-pipelines versions, got %d", workflow, len(versions))
+			}
+			for i := 1; i < len(versions); i++ {
+				from, to := versions[i-1], versions[i]
+				tests = append(tests, clusterTest(repo,
+					fmt.Sprintf("osp-%s-to-%s-ocp-%s-upgrade", k8sNameString(from), k8sNameString(to), ocpVersion),
+					literalStep("install", fmt.Sprintf("make OSP_VERSION=%s install-openshift-pipelines", from)),
+					literalStep("upgrade", fmt.Sprintf("make OSP_VERSION=%s upgrade-openshift-pipelines", to)),
+					literalStep("e2e", fmt.Sprintf("make OSP_VERSION=%s test-e2e", to)),
+				))
+			}
+		default:
+			return tests, fmt.Errorf("unknown workflow %q", workflow)
 		}
-	default:
-		return tests, fmt.Errorf("unknown workflow %q", repo.E2E.Workflow)
 	}
 	return tests, nil
 }
 
+// sortedVersions returns the OpenShift Pipelines versions, e.g. 1.15, from the
+// oldest to the newest.
+func sortedVersions(versions []string) ([]string, error) {
+	for _, version := range versions {
+		if !semver.IsValid("v" + version) {
+			return nil, fmt.Errorf("invalid openshift-pipelines version %q", version)
+		}
+	}
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b string) int {
+		return semver.Compare("v"+a, "v"+b)
+	})
+	return sorted, nil
+}
+
+// clusterTest returns a test running the steps on a claimed cluster, gathering
+// the cluster state afterwards.
+func clusterTest(repo *Repository, as string, steps ...cioperatorapi.TestStep) cioperatorapi.TestStepConfiguration {
+	return cioperatorapi.TestStepConfiguration{
+		As:           as,
+		Cluster:      "build05",
+		ClusterClaim: getClusterClaim(repo.OpenShift.Version),
+		MultiStageTestConfiguration: &cioperatorapi.MultiStageTestConfiguration{
+			AllowSkipOnSuccess:       pTrue(),
+			AllowBestEffortPostSteps: pTrue(),
+			Post:                     getTaskPostSteps(),
+			Test:                     steps,
+			Workflow:                 stringPtr("generic-claim"),
+		},
+		SkipIfOnlyChanged: skipIfOnlyChanged,
+	}
+}
+
+func literalStep(as, commands string) cioperatorapi.TestStep {
+	return cioperatorapi.TestStep{
+		LiteralTestStep: &cioperatorapi.LiteralTestStep{
+			As:       as,
+			Cli:      "latest",
+			Commands: commands,
+			From:     "base-tests",
+			Resources: cioperatorapi.ResourceRequirements{
+				Requests: cioperatorapi.ResourceList{
+					"cpu": "100m",
+				},
+			},
+		},
+	}
+}
+
 func getTaskPostSteps() []cioperatorapi.TestStep {
 	return []cioperatorapi.TestStep{{
 		LiteralTestStep: &cioperatorapi.LiteralTestStep{
@@ -286,7 +377,7 @@ func getTaskPostSteps() []cioperatorapi.TestStep {
 					"cpu": "100m",
 				},
 			},
-			Timeout: &prowv1.Duration{time.Duration(20) * time.Minute},
+			Timeout: &prowv1.Duration{Duration: time.Duration(20) * time.Minute},
 		},
 	}, {
 		LiteralTestStep: &cioperatorapi.LiteralTestStep{
@@ -301,7 +392,7 @@ func getTaskPostSteps() []cioperatorapi.TestStep {
 					"cpu": "100m",
 				},
 			},
-			Timeout: &prowv1.Duration{time.Duration(20) * time.Minute},
+			Timeout: &prowv1.Duration{Duration: time.Duration(20) * time.Minute},
 		},
 	}, {
 		LiteralTestStep: &cioperatorapi.LiteralTestStep{
@@ -311,14 +402,14 @@ func getTaskPostSteps() []cioperatorapi.TestStep {
 			Cli:               "latest",
 			Commands:          "curl -skSL https://raw.githubusercontent.com/openshift/release/master/ci-operator/step-registry/gather/extra/gather-extra-commands.sh | /bin/bash -s",
 			From:              "base-tests",
-			GracePeriod:       &prowv1.Duration{time.Duration(1) * time.Minute},
+			GracePeriod:       &prowv1.Duration{Duration: time.Duration(1) * time.Minute},
 			Resources: cioperatorapi.ResourceRequirements{
 				Requests: cioperatorapi.ResourceList{
 					"cpu":    "300m",
 					"memory": "300Mi",
 				},
 			},
-			Timeout: &prowv1.Duration{time.Duration(20) * time.Minute},
+			Timeout: &prowv1.Duration{Duration: time.Duration(20) * time.Minute},
 		},
 	}}
 }
@@ -330,7 +421,7 @@ func getClusterClaim(ocpVersion string) *cioperatorapi.ClusterClaim {
 		Cloud:        "openstack",
 		Owner:        "pipelines",
 		Product:      cioperatorapi.ReleaseProductOCP,
-		Timeout:      &prowv1.Duration{time.Duration(60) * time.Minute},
+		Timeout:      &prowv1.Duration{Duration: time.Duration(60) * time.Minute},
 		Version:      ocpVersion,
 	}
 }
@@ -0,0 +1,138 @@
+package prowgen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+)
+
+// testCommands summarizes a generated test as its name followed by the
+// commands of its steps, e.g. "unit: make test-unit".
+func testCommands(test cioperatorapi.TestStepConfiguration) string {
+	var commands []string
+	if test.ContainerTestConfiguration != nil {
+		commands = append(commands, test.Commands)
+	}
+	if test.MultiStageTestConfiguration != nil {
+		for _, step := range test.MultiStageTestConfiguration.Test {
+			commands = append(commands, step.Commands)
+		}
+	}
+	return test.As + ": " + strings.Join(commands, "; ")
+}
+
+func TestGenerateTestFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		e2e      E2E
+		versions []string
+		want     []string
+		wantErr  string
+	}{{
+		name:     "tasks",
+		e2e:      E2E{Workflow: "tasks"},
+		versions: []string{"1.15", "1.16"},
+		want: []string{
+			"osp-115-ocp-416-e2e: make OSP_VERSION=1.15 test-e2e-openshift",
+			"osp-116-ocp-416-e2e: make OSP_VERSION=1.16 test-e2e-openshift",
+		},
+	}, {
+		name:     "unit",
+		e2e:      E2E{Workflow: "unit"},
+		versions: []string{"1.15"},
+		want:     []string{"unit: make test-unit"},
+	}, {
+		name:     "operator",
+		e2e:      E2E{Workflow: "operator"},
+		versions: []string{"1.15", "1.16"},
+		want: []string{
+			"osp-115-ocp-416-operator-e2e: make OSP_VERSION=1.15 install-openshift-pipelines; make OSP_VERSION=1.15 test-e2e",
+			"osp-116-ocp-416-operator-e2e: make OSP_VERSION=1.16 install-openshift-pipelines; make OSP_VERSION=1.16 test-e2e",
+		},
+	}, {
+		name:     "upgrade in version order",
+		e2e:      E2E{Workflow: "upgrade"},
+		versions: []string{"1.10", "1.9", "1.11"},
+		want: []string{
+			"osp-19-to-110-ocp-416-upgrade: make OSP_VERSION=1.9 install-openshift-pipelines; make OSP_VERSION=1.10 upgrade-openshift-pipelines; make OSP_VERSION=1.10 test-e2e",
+			"osp-110-to-111-ocp-416-upgrade: make OSP_VERSION=1.10 install-openshift-pipelines; make OSP_VERSION=1.11 upgrade-openshift-pipelines; make OSP_VERSION=1.11 test-e2e",
+		},
+	}, {
+		name:     "additional workflows",
+		e2e:      E2E{Workflow: "operator", Workflows: []string{"unit"}},
+		versions: []string{"1.16"},
+		want: []string{
+			"osp-116-ocp-416-operator-e2e: make OSP_VERSION=1.16 install-openshift-pipelines; make OSP_VERSION=1.16 test-e2e",
+			"unit: make test-unit",
+		},
+	}, {
+		name:     "only additional workflows",
+		e2e:      E2E{Workflows: []string{"unit"}},
+		versions: []string{"1.16"},
+		want:     []string{"unit: make test-unit"},
+	}, {
+		name:     "upgrade with a single version",
+		e2e:      E2E{Workflow: "upgrade"},
+		versions: []string{"1.16"},
+		wantErr:  "needs at least two openshift-pipelines versions",
+	}, {
+		name:     "upgrade with an invalid version",
+		e2e:      E2E{Workflow: "upgrade"},
+		versions: []string{"1.16", "next"},
+		wantErr:  `invalid openshift-pipelines version "next"`,
+	}, {
+		name:     "unknown workflow",
+		e2e:      E2E{Workflow: "smoke"},
+		versions: []string{"1.16"},
+		wantErr:  `unknown workflow "smoke"`,
+	}, {
+		name:     "no workflow",
+		versions: []string{"1.16"},
+		wantErr:  "no workflow configured",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{
+				Repo:               "tektoncd-pipeline",
+				OpenShift:          OpenShift{Version: "4.16"},
+				OpenShiftPipelines: OpenShiftPipelines{Versions: tt.versions},
+				E2E:                tt.e2e,
+			}
+			got, err := generateTestFromConfig(repo)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("generateTestFromConfig() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var commands []string
+			for _, test := range got {
+				commands = append(commands, testCommands(test))
+				if test.SkipIfOnlyChanged != skipIfOnlyChanged {
+					t.Errorf("%s: SkipIfOnlyChanged = %q", test.As, test.SkipIfOnlyChanged)
+				}
+				if test.MultiStageTestConfiguration != nil && (test.ClusterClaim == nil || test.ClusterClaim.Version != "4.16") {
+					t.Errorf("%s: ClusterClaim = %+v, want OpenShift 4.16", test.As, test.ClusterClaim)
+				}
+			}
+			if !slices.Equal(commands, tt.want) {
+				t.Errorf("tests:\n%s\nwant:\n%s", strings.Join(commands, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	// The versions of the config are left in their order.
+	versions := []string{"1.10", "1.9"}
+	repo := &Repository{OpenShiftPipelines: OpenShiftPipelines{Versions: versions}, E2E: E2E{Workflow: "upgrade"}}
+	if _, err := generateTestFromConfig(repo); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions, []string{"1.10", "1.9"}) {
+		t.Errorf("versions of the config reordered: %v", versions)
+	}
+}